@@ -0,0 +1,85 @@
+// profile-lint checks device profile files and reports all their problems with
+// their location. A profile extending other profiles is flattened with the base
+// profiles found among the files. It exits with 1 if a profile has problems and 2
+// if a file cannot be read.
+//
+//	profile-lint res/Simple-Driver.yaml res/other.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/profilelint"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: profile-lint <profile.yaml|profile.json>...")
+		os.Exit(2)
+	}
+
+	status := 0
+	paths := make(map[string]string)
+	profiles := make(map[string]models.DeviceProfile)
+	for _, path := range os.Args[1:] {
+		profile, err := readProfile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			status = 2
+			continue
+		}
+		paths[path] = profile.Name
+		profiles[profile.Name] = dtos.ToDeviceProfileModel(profile)
+	}
+
+	source := func(name string) (models.DeviceProfile, errors.EdgeX) {
+		profile, ok := profiles[name]
+		if !ok {
+			return profile, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("profile %s is not among the files", name), nil)
+		}
+		return profile, nil
+	}
+	for _, path := range os.Args[1:] {
+		name, ok := paths[path]
+		if !ok {
+			continue
+		}
+		profile, err := cache.FlattenProfile(profiles[name], source)
+		if err != nil {
+			fmt.Printf("%s: extends: %s\n", path, err.Message())
+			status = 1
+			continue
+		}
+		for _, problem := range profilelint.Lint(profile) {
+			fmt.Printf("%s: %s\n", path, problem)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// readProfile reads a JSON profile, or a YAML profile for any other extension
+func readProfile(path string) (dtos.DeviceProfile, error) {
+	var profile dtos.DeviceProfile
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return profile, err
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(data, &profile)
+	} else {
+		err = yaml.Unmarshal(data, &profile)
+	}
+	return profile, err
+}
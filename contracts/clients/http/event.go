@@ -42,6 +42,16 @@ func (ec *eventClient) Add(ctx context.Context, req requests.AddEventRequest) (
 	return br, nil
 }
 
+func (ec *eventClient) AddBatch(ctx context.Context, reqs []requests.AddEventRequest) (
+	[]common.BaseWithIdResponse, errors.EdgeX) {
+	var res []common.BaseWithIdResponse
+	err := utils.PostRequest(ctx, &res, ec.baseUrl+contracts.ApiEventBatchRoute, reqs)
+	if err != nil {
+		return res, errors.NewCommonEdgeXWrapper(err)
+	}
+	return res, nil
+}
+
 func (ec *eventClient) AllEvents(ctx context.Context, offset, limit int) (responses.MultiEventsResponse, errors.EdgeX) {
 	requestParams := url.Values{}
 	requestParams.Set(contracts.Offset, strconv.Itoa(offset))
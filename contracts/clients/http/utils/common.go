@@ -16,6 +16,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -181,10 +182,11 @@ func sendRequest(ctx context.Context, req *http.Request) ([]byte, errors.EdgeX)
 		return bodyBytes, nil
 	}
 
-	// Handle error response
+	// Handle error response, the service may answer a plain text body, e.g. for an unknown route
 	var res common.BaseResponse
-	if err := json.Unmarshal(bodyBytes, &res); err != nil {
-		return nil, errors.NewCommonEdgeXWrapper(err)
+	if err := json.Unmarshal(bodyBytes, &res); err != nil || res.StatusCode == 0 {
+		res.StatusCode = resp.StatusCode
+		res.Message = strings.TrimSpace(string(bodyBytes))
 	}
 	msg := fmt.Sprintf("request failed, status code: %d, err: %s", res.StatusCode, res.Message)
 	errKind := errors.KindMapping(res.StatusCode)
@@ -18,6 +18,9 @@ import (
 type EventClient interface {
 	// Add adds new event.
 	Add(ctx context.Context, req requests.AddEventRequest) (common.BaseWithIdResponse, errors.EdgeX)
+	// AddBatch adds several events in a single request, the responses are in the order of the requests.
+	// Core-data versions without the batch endpoint fail with a KindEntityDoesNotExist or KindNotAllowed error.
+	AddBatch(ctx context.Context, reqs []requests.AddEventRequest) ([]common.BaseWithIdResponse, errors.EdgeX)
 	// AllEvents returns all events sorted in descending order of created time.
 	// The result can be limited in a certain range by specifying the offset and limit parameters.
 	// offset: The number of items to skip before starting to collect the result set. Default is 0.
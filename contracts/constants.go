@@ -35,6 +35,7 @@ const (
 	ApiEventByDeviceNameRoute          = ApiEventRoute + "/" + Device + "/" + Name + "/{" + Name + "}"
 	ApiEventByTimeRangeRoute           = ApiEventRoute + "/" + Start + "/{" + Start + "}/" + End + "/{" + End + "}"
 	ApiEventByAgeRoute                 = ApiEventRoute + "/" + Age + "/{" + Age + "}"
+	ApiEventBatchRoute                 = ApiEventRoute + "/" + Batch
 
 	ApiReadingRoute                            = ApiBase + "/reading"
 	ApiAllReadingRoute                         = ApiReadingRoute + "/" + All
@@ -74,6 +75,7 @@ const (
 	ApiDeviceByServiceIdRoute     = ApiDeviceRoute + "/" + Service + "/" + Id + "/{" + Id + "}"
 	ApiDeviceByServiceNameRoute   = ApiDeviceRoute + "/" + Service + "/" + Name + "/{" + Name + "}"
 	ApiDeviceNameCommandNameRoute = ApiDeviceByNameRoute + "/{" + Command + "}"
+	ApiDeviceNameTagRoute         = ApiDeviceByNameRoute + "/tag/{tag}"
 	ApiDeviceCountRoute           = ApiDeviceRoute + "/" + Count
 	ApiDeviceActiveRoute          = ApiDeviceRoute + "/active"
 	ApiDeviceSearchRoute          = ApiDeviceRoute + "/" + Search
@@ -101,6 +103,26 @@ const (
 	ApiWatcherCallbackNameRoute = ApiBase + "/callback/watcher/name/{name}"
 	ApiServiceCallbackRoute     = ApiBase + "/callback/service"
 	ApiDiscoveryRoute           = ApiBase + "/discovery"
+	ApiDiscoveryExplainRoute    = ApiDiscoveryRoute + "/explain"
+	ApiDiscoveryJobRoute        = ApiDiscoveryRoute + "/job"
+	ApiAllDiscoveryJobRoute     = ApiDiscoveryJobRoute + "/" + All
+	ApiDiscoveryJobByIdRoute    = ApiDiscoveryJobRoute + "/" + Id + "/{" + Id + "}"
+	ApiOpenAPIRoute             = ApiBase + "/openapi"
+
+	// value descriptors generated from the device resources of the cached profiles
+	ApiAllValueDescriptorRoute      = ApiBase + "/valuedescriptor/" + All
+	ApiProfileValueDescriptorsRoute = ApiBase + "/valuedescriptor/" + Profile + "/" + Name + "/{" + Name + "}"
+	ApiResourceValueDescriptorRoute = ApiProfileValueDescriptorsRoute + "/resource/{" + ResourceName + "}"
+
+	// read-only views of the caches of the device service
+	ApiCacheRoute                       = ApiBase + "/cache"
+	ApiCacheAllDeviceRoute              = ApiCacheRoute + "/" + Device + "/" + All
+	ApiCacheDeviceByNameRoute           = ApiCacheRoute + "/" + Device + "/" + Name + "/{" + Name + "}"
+	ApiCacheAllProfileRoute             = ApiCacheRoute + "/" + Profile + "/" + All
+	ApiCacheProfileByNameRoute          = ApiCacheRoute + "/" + Profile + "/" + Name + "/{" + Name + "}"
+	ApiCacheAllProvisionWatcherRoute    = ApiCacheRoute + "/provisionwatcher/" + All
+	ApiCacheProvisionWatcherByNameRoute = ApiCacheRoute + "/provisionwatcher/" + Name + "/{" + Name + "}"
+	ApiCacheAllAutoEventRoute           = ApiCacheRoute + "/autoevent/" + All
 
 	//功能点
 	ApiFuncPointRoute       = ApiDeviceProfileRoute + "/{" + DeviceProfileId + "}" + "/func_point"
@@ -128,6 +150,7 @@ const (
 // Constants related to defined url path names and parameters in the v2 service APIs
 const (
 	All             = "all"
+	Batch           = "batch"
 	Id              = "id"
 	Created         = "created"
 	Modified        = "modified"
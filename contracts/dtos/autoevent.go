@@ -12,16 +12,35 @@ import "github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 type AutoEvent struct {
 	Frequency string `json:"frequency" validate:"required,edgex-dto-frequency"`
 	OnChange  bool   `json:"onChange,omitempty"`
-	Resource  string `json:"resource" validate:"required"`
+	Resource  string `json:"resource,omitempty" validate:"required_without=Tag"`
+	// Tag is an extension of the APIv2 AutoEvent, the AutoEvent reads all the device
+	// resources having the tag instead of a single resource
+	Tag string `json:"tag,omitempty" validate:"required_without=Resource"`
+	// Aggregation is an extension of the APIv2 AutoEvent, see models.AutoEventAggregation
+	Aggregation *AutoEventAggregation `json:"aggregation,omitempty"`
+}
+
+// AutoEventAggregation configures the windowed aggregation of an AutoEvent
+type AutoEventAggregation struct {
+	Window    string   `json:"window" validate:"required,edgex-dto-frequency"`
+	Functions []string `json:"functions,omitempty" validate:"dive,oneof=min max mean last count"`
 }
 
 // ToAutoEventModel transforms the AutoEvent DTO to the AutoEvent model
 func ToAutoEventModel(a AutoEvent) models.AutoEvent {
-	return models.AutoEvent{
+	autoEvent := models.AutoEvent{
 		Frequency: a.Frequency,
 		OnChange:  a.OnChange,
 		Resource:  a.Resource,
+		Tag:       a.Tag,
+	}
+	if a.Aggregation != nil {
+		autoEvent.Aggregation = models.AutoEventAggregation{
+			Window:    a.Aggregation.Window,
+			Functions: a.Aggregation.Functions,
+		}
 	}
+	return autoEvent
 }
 
 // ToAutoEventModels transforms the AutoEvent DTO array to the AutoEvent model array
@@ -35,11 +54,19 @@ func ToAutoEventModels(autoEventDTOs []AutoEvent) []models.AutoEvent {
 
 // FromAutoEventModelToDTO transforms the AutoEvent model to the AutoEvent DTO
 func FromAutoEventModelToDTO(a models.AutoEvent) AutoEvent {
-	return AutoEvent{
+	autoEvent := AutoEvent{
 		Frequency: a.Frequency,
 		OnChange:  a.OnChange,
 		Resource:  a.Resource,
+		Tag:       a.Tag,
 	}
+	if a.Aggregation.Window != "" {
+		autoEvent.Aggregation = &AutoEventAggregation{
+			Window:    a.Aggregation.Window,
+			Functions: a.Aggregation.Functions,
+		}
+	}
+	return autoEvent
 }
 
 // ToAutoEventModels transforms the AutoEvent model array to the AutoEvent DTO array
@@ -50,3 +77,9 @@ func FromAutoEventModelsToDTOs(autoEvents []models.AutoEvent) []AutoEvent {
 	}
 	return dtos
 }
+
+// DeviceAutoEvents lists the AutoEvents a device service is executing for a device
+type DeviceAutoEvents struct {
+	DeviceName string      `json:"deviceName"`
+	AutoEvents []AutoEvent `json:"autoEvents"`
+}
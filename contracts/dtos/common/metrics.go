@@ -14,6 +14,15 @@ type Metrics struct {
 	MemSys         uint64 `json:"memSys"`
 	MemTotalAlloc  uint64 `json:"memTotalAlloc"`
 	CpuBusyAvg     uint8  `json:"cpuBusyAvg"`
+	// AsyncDroppedOldest and AsyncDroppedNewest count the asynchronous readings
+	// discarded by the drop-oldest and drop-newest overflow policies
+	AsyncDroppedOldest uint64 `json:"asyncDroppedOldest,omitempty"`
+	AsyncDroppedNewest uint64 `json:"asyncDroppedNewest,omitempty"`
+	// AsyncRejected counts the rejected asynchronous readings by reason
+	AsyncRejected map[string]uint64 `json:"asyncRejected,omitempty"`
+	// CacheDrift counts the differences between the caches and Core Metadata fixed
+	// by the reconciliations, by kind of difference
+	CacheDrift map[string]uint64 `json:"cacheDrift,omitempty"`
 }
 
 // MetricsResponse defines the providing memory and cpu utilization stats of the service.
@@ -24,6 +24,7 @@ type DeviceProfile struct {
 	Description        string            `json:"description,omitempty" yaml:"description,omitempty"`
 	Model              string            `json:"model,omitempty" yaml:"model,omitempty"`
 	Labels             []string          `json:"labels,omitempty" yaml:"labels,flow,omitempty"`
+	Extends            []string          `json:"extends,omitempty" yaml:"extends,flow,omitempty"`
 	DeviceResources    []DeviceResource  `json:"deviceResources,omitempty" yaml:"deviceResources" validate:"gte=0,dive"`
 	DeviceCommands     []ProfileResource `json:"deviceCommands,omitempty" yaml:"deviceCommands,omitempty" validate:"dive"`
 	CoreCommands       []Command         `json:"coreCommands,omitempty" yaml:"coreCommands,omitempty" validate:"dive"`
@@ -40,6 +41,7 @@ func ToDeviceProfileModel(deviceProfileDTO DeviceProfile) models.DeviceProfile {
 		Manufacturer:    deviceProfileDTO.Manufacturer,
 		Model:           deviceProfileDTO.Model,
 		Labels:          deviceProfileDTO.Labels,
+		Extends:         deviceProfileDTO.Extends,
 		DeviceResources: ToDeviceResourceModels(deviceProfileDTO.DeviceResources),
 		DeviceCommands:  ToProfileResourceModels(deviceProfileDTO.DeviceCommands),
 		CoreCommands:    ToCommandModels(deviceProfileDTO.CoreCommands),
@@ -58,6 +60,7 @@ func FromDeviceProfileModelToDTO(deviceProfile models.DeviceProfile) DeviceProfi
 		Manufacturer:    deviceProfile.Manufacturer,
 		Model:           deviceProfile.Model,
 		Labels:          deviceProfile.Labels,
+		Extends:         deviceProfile.Extends,
 		DeviceResources: FromDeviceResourceModelsToDTOs(deviceProfile.DeviceResources),
 		DeviceCommands:  FromProfileResourceModelsToDTOs(deviceProfile.DeviceCommands),
 		CoreCommands:    FromCommandModelsToDTOs(deviceProfile.CoreCommands),
@@ -66,6 +69,10 @@ func FromDeviceProfileModelToDTO(deviceProfile models.DeviceProfile) DeviceProfi
 }
 
 func ValidateDeviceProfileDTO(profile DeviceProfile) error {
+	// the references to the resources and commands of the base profiles are
+	// checked once the profile is flattened
+	extends := len(profile.Extends) > 0
+
 	// deviceResources validation
 	dupCheck := make(map[string]bool)
 	for _, resource := range profile.DeviceResources {
@@ -87,13 +94,13 @@ func ValidateDeviceProfileDTO(profile DeviceProfile) error {
 		// deviceResources referenced in deviceCommands must exist
 		getCommands := command.Get
 		for _, getCommand := range getCommands {
-			if !deviceResourcesContains(profile.DeviceResources, getCommand.DeviceResource) {
+			if !extends && !deviceResourcesContains(profile.DeviceResources, getCommand.DeviceResource) {
 				return errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("device command's Get resource %s doesn't match any deivce resource", getCommand.DeviceResource), nil)
 			}
 		}
 		setCommands := command.Set
 		for _, setCommand := range setCommands {
-			if !deviceResourcesContains(profile.DeviceResources, setCommand.DeviceResource) {
+			if !extends && !deviceResourcesContains(profile.DeviceResources, setCommand.DeviceResource) {
 				return errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("device command's Set resource %s doesn't match any deivce resource", setCommand.DeviceResource), nil)
 			}
 		}
@@ -108,7 +115,7 @@ func ValidateDeviceProfileDTO(profile DeviceProfile) error {
 		dupCheck[command.Name] = true
 
 		// coreCommands name should match the one of deviceResources and deviceCommands
-		if !deviceCommandsContains(profile.DeviceCommands, command.Name) &&
+		if !extends && !deviceCommandsContains(profile.DeviceCommands, command.Name) &&
 			!deviceResourcesContains(profile.DeviceResources, command.Name) {
 			return errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("core command %s doesn't match any deivce command or resource", command.Name), nil)
 		}
@@ -0,0 +1,16 @@
+package dtos
+
+// DiscoveredDevice is a device found by the protocol discovery of a device service
+type DiscoveredDevice struct {
+	Name        string                        `json:"name"`
+	Protocols   map[string]ProtocolProperties `json:"protocols"`
+	Description string                        `json:"description,omitempty"`
+	Labels      []string                      `json:"labels,omitempty"`
+}
+
+// WatcherDecision explains whether a ProvisionWatcher accepts a DiscoveredDevice
+type WatcherDecision struct {
+	ProvisionWatcherName string   `json:"provisionWatcherName"`
+	Accepted             bool     `json:"accepted"`
+	Reasons              []string `json:"reasons,omitempty"`
+}
@@ -0,0 +1,17 @@
+package dtos
+
+// DiscoveryJob describes a discovery run of a device service and the devices it found,
+// the devices are named as discovered by the driver
+type DiscoveryJob struct {
+	Id       string            `json:"id"`
+	Status   string            `json:"status"`
+	Options  map[string]string `json:"options,omitempty"`
+	Started  int64             `json:"started"`
+	Finished int64             `json:"finished,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Found    []string          `json:"found,omitempty"`
+	Added    []string          `json:"added,omitempty"`
+	Updated  []string          `json:"updated,omitempty"`
+	// Rejected maps the names of the devices not added to the reason
+	Rejected map[string]string `json:"rejected,omitempty"`
+}
@@ -23,6 +23,9 @@ type ProvisionWatcher struct {
 	ServiceName         string              `json:"serviceName" validate:"required,edgex-dto-none-empty-string,edgex-dto-rfc3986-unreserved-chars"`
 	AdminState          string              `json:"adminState" validate:"oneof='LOCKED' 'UNLOCKED'"`
 	AutoEvents          []AutoEvent         `json:"autoEvents,omitempty" validate:"dive"`
+	DeviceNameTemplate  string              `json:"deviceNameTemplate,omitempty"`
+	DeviceLabels        []string            `json:"deviceLabels,omitempty"`
+	IdentityKeys        []string            `json:"identityKeys,omitempty"`
 }
 
 // UpdateProvisionWatcher and its properties are defined in the APIv2 specification:
@@ -38,6 +41,9 @@ type UpdateProvisionWatcher struct {
 	ServiceName         *string             `json:"serviceName" validate:"omitempty,edgex-dto-none-empty-string,edgex-dto-rfc3986-unreserved-chars"`
 	AdminState          *string             `json:"adminState" validate:"omitempty,oneof='LOCKED' 'UNLOCKED'"`
 	AutoEvents          []AutoEvent         `json:"autoEvents" validate:"dive"`
+	DeviceNameTemplate  *string             `json:"deviceNameTemplate"`
+	DeviceLabels        []string            `json:"deviceLabels"`
+	IdentityKeys        []string            `json:"identityKeys"`
 }
 
 // ToProvisionWatcherModel transforms the ProvisionWatcher DTO to the ProvisionWatcher model
@@ -53,6 +59,9 @@ func ToProvisionWatcherModel(dto ProvisionWatcher) models.ProvisionWatcher {
 		ServiceName:         dto.ServiceName,
 		AdminState:          models.AdminState(dto.AdminState),
 		AutoEvents:          ToAutoEventModels(dto.AutoEvents),
+		DeviceNameTemplate:  dto.DeviceNameTemplate,
+		DeviceLabels:        dto.DeviceLabels,
+		IdentityKeys:        dto.IdentityKeys,
 	}
 }
 
@@ -68,6 +77,9 @@ func FromProvisionWatcherModelToDTO(pw models.ProvisionWatcher) ProvisionWatcher
 		ServiceName:         pw.ServiceName,
 		AdminState:          string(pw.AdminState),
 		AutoEvents:          FromAutoEventModelsToDTOs(pw.AutoEvents),
+		DeviceNameTemplate:  pw.DeviceNameTemplate,
+		DeviceLabels:        pw.DeviceLabels,
+		IdentityKeys:        pw.IdentityKeys,
 	}
 }
 
@@ -84,5 +96,8 @@ func FromProvisionWatcherModelToUpdateDTO(pw models.ProvisionWatcher) UpdateProv
 		ServiceName:         &pw.ServiceName,
 		AdminState:          &adminState,
 		AutoEvents:          FromAutoEventModelsToDTOs(pw.AutoEvents),
+		DeviceNameTemplate:  &pw.DeviceNameTemplate,
+		DeviceLabels:        pw.DeviceLabels,
+		IdentityKeys:        pw.IdentityKeys,
 	}
 }
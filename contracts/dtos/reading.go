@@ -37,6 +37,7 @@ type BaseReading struct {
 	ValueType          string `json:"valueType" validate:"required,edgex-dto-value-type"`
 	BinaryReading      `json:",inline" validate:"-"`
 	SimpleReading      `json:",inline" validate:"-"`
+	Tags               map[string]string `json:"tags,omitempty" xml:"-"` // Have to ignore since map not supported for XML
 }
 
 // SimpleReading and its properties are defined in the APIv2 specification:
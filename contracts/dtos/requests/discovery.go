@@ -0,0 +1,12 @@
+package requests
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+)
+
+// DiscoveryRequest defines the Request Content for POST discovery, the body is optional.
+// Options are driver-specific, such as a subnet, a port range or a timeout.
+type DiscoveryRequest struct {
+	common.BaseRequest `json:",inline"`
+	Options            map[string]string `json:"options,omitempty"`
+}
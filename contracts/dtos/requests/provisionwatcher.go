@@ -113,4 +113,13 @@ func ReplaceProvisionWatcherModelFieldsWithDTO(pw *models.ProvisionWatcher, patc
 	if patch.AutoEvents != nil {
 		pw.AutoEvents = dtos.ToAutoEventModels(patch.AutoEvents)
 	}
+	if patch.DeviceNameTemplate != nil {
+		pw.DeviceNameTemplate = *patch.DeviceNameTemplate
+	}
+	if patch.DeviceLabels != nil {
+		pw.DeviceLabels = patch.DeviceLabels
+	}
+	if patch.IdentityKeys != nil {
+		pw.IdentityKeys = patch.IdentityKeys
+	}
 }
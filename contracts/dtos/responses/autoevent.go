@@ -0,0 +1,19 @@
+package responses
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+)
+
+// MultiDeviceAutoEventsResponse defines the Response Content for GET the running AutoEvents of a device service
+type MultiDeviceAutoEventsResponse struct {
+	common.BaseResponse `json:",inline"`
+	Devices             []dtos.DeviceAutoEvents `json:"devices"`
+}
+
+func NewMultiDeviceAutoEventsResponse(requestId string, message string, statusCode int, devices []dtos.DeviceAutoEvents) MultiDeviceAutoEventsResponse {
+	return MultiDeviceAutoEventsResponse{
+		BaseResponse: common.NewBaseResponse(requestId, message, statusCode),
+		Devices:      devices,
+	}
+}
@@ -0,0 +1,48 @@
+package responses
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+)
+
+// ExplainDiscoveryResponse defines the Response Content for the evaluation of a DiscoveredDevice
+// against the ProvisionWatchers of the device service
+type ExplainDiscoveryResponse struct {
+	common.BaseResponse `json:",inline"`
+	Accepted            bool                   `json:"accepted"`
+	Decisions           []dtos.WatcherDecision `json:"decisions"`
+}
+
+func NewExplainDiscoveryResponse(requestId string, message string, statusCode int, accepted bool, decisions []dtos.WatcherDecision) ExplainDiscoveryResponse {
+	return ExplainDiscoveryResponse{
+		BaseResponse: common.NewBaseResponse(requestId, message, statusCode),
+		Accepted:     accepted,
+		Decisions:    decisions,
+	}
+}
+
+// DiscoveryJobResponse defines the Response Content for GET DiscoveryJob DTO.
+type DiscoveryJobResponse struct {
+	common.BaseResponse `json:",inline"`
+	Job                 dtos.DiscoveryJob `json:"job"`
+}
+
+func NewDiscoveryJobResponse(requestId string, message string, statusCode int, job dtos.DiscoveryJob) DiscoveryJobResponse {
+	return DiscoveryJobResponse{
+		BaseResponse: common.NewBaseResponse(requestId, message, statusCode),
+		Job:          job,
+	}
+}
+
+// MultiDiscoveryJobsResponse defines the Response Content for GET multiple DiscoveryJob DTOs.
+type MultiDiscoveryJobsResponse struct {
+	common.BaseResponse `json:",inline"`
+	Jobs                []dtos.DiscoveryJob `json:"jobs"`
+}
+
+func NewMultiDiscoveryJobsResponse(requestId string, message string, statusCode int, jobs []dtos.DiscoveryJob) MultiDiscoveryJobsResponse {
+	return MultiDiscoveryJobsResponse{
+		BaseResponse: common.NewBaseResponse(requestId, message, statusCode),
+		Jobs:         jobs,
+	}
+}
@@ -0,0 +1,34 @@
+package responses
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+)
+
+// ValueDescriptorResponse defines the Response Content for GET the ValueDescriptor of a device resource
+type ValueDescriptorResponse struct {
+	common.BaseResponse `json:",inline"`
+	ValueDescriptor     dtos.ValueDescriptor `json:"valueDescriptor"`
+}
+
+func NewValueDescriptorResponse(requestId string, message string, statusCode int, valueDescriptor dtos.ValueDescriptor) ValueDescriptorResponse {
+	return ValueDescriptorResponse{
+		BaseResponse:    common.NewBaseResponse(requestId, message, statusCode),
+		ValueDescriptor: valueDescriptor,
+	}
+}
+
+// MultiValueDescriptorsResponse defines the Response Content for GET multiple ValueDescriptor DTOs
+type MultiValueDescriptorsResponse struct {
+	common.BaseResponse `json:",inline"`
+	Total               uint32                 `json:"total"`
+	ValueDescriptors    []dtos.ValueDescriptor `json:"valueDescriptors"`
+}
+
+func NewMultiValueDescriptorsResponse(requestId string, message string, statusCode int, valueDescriptors []dtos.ValueDescriptor) MultiValueDescriptorsResponse {
+	return MultiValueDescriptorsResponse{
+		BaseResponse:     common.NewBaseResponse(requestId, message, statusCode),
+		Total:            uint32(len(valueDescriptors)),
+		ValueDescriptors: valueDescriptors,
+	}
+}
@@ -0,0 +1,47 @@
+package dtos
+
+import "github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+
+// ValueDescriptor describes the values of the readings of a device resource of a profile,
+// so the readings can be interpreted without fetching the profile
+type ValueDescriptor struct {
+	ProfileName   string      `json:"profileName"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description,omitempty"`
+	Type          string      `json:"type"`
+	Min           interface{} `json:"min,omitempty"`
+	Max           interface{} `json:"max,omitempty"`
+	DefaultValue  interface{} `json:"defaultValue,omitempty"`
+	UomLabel      string      `json:"uomLabel,omitempty"`
+	MediaType     string      `json:"mediaType,omitempty"`
+	FloatEncoding string      `json:"floatEncoding,omitempty"`
+	Labels        []string    `json:"labels,omitempty"`
+}
+
+// FromValueDescriptorModelToDTO transforms the ValueDescriptor model of a device resource
+// of the given profile to the ValueDescriptor DTO
+func FromValueDescriptorModelToDTO(profileName string, vd models.ValueDescriptor) ValueDescriptor {
+	return ValueDescriptor{
+		ProfileName:   profileName,
+		Name:          vd.Name,
+		Description:   vd.Description,
+		Type:          vd.Type,
+		Min:           vd.Min,
+		Max:           vd.Max,
+		DefaultValue:  vd.DefaultValue,
+		UomLabel:      vd.UomLabel,
+		MediaType:     vd.MediaType,
+		FloatEncoding: vd.FloatEncoding,
+		Labels:        vd.Labels,
+	}
+}
+
+// FromValueDescriptorModelsToDTOs transforms the ValueDescriptor models of the given profile
+// to the ValueDescriptor DTOs
+func FromValueDescriptorModelsToDTOs(profileName string, vds []models.ValueDescriptor) []ValueDescriptor {
+	dtos := make([]ValueDescriptor, len(vds))
+	for i, vd := range vds {
+		dtos[i] = FromValueDescriptorModelToDTO(profileName, vd)
+	}
+	return dtos
+}
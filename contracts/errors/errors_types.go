@@ -34,6 +34,8 @@ const (
 	KindRangeNotSatisfiable ErrKind = "RangeNotSatisfiable"
 	KindClientError         ErrKind = "ClientError"
 	KindIOError             ErrKind = "IOError"
+	KindUnauthorized        ErrKind = "Unauthorized"
+	KindForbidden           ErrKind = "Forbidden"
 )
 
 // Error codes are not defined in HTTP status codes
@@ -210,6 +212,10 @@ func codeMapping(kind ErrKind) int {
 		return http.StatusRequestedRangeNotSatisfiable
 	case KindClientError, KindIOError:
 		return ClientErrorCode
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
 	default:
 		return http.StatusInternalServerError
 	}
@@ -242,6 +248,10 @@ func KindMapping(code int) ErrKind {
 		return KindRangeNotSatisfiable
 	case ClientErrorCode:
 		return KindClientError
+	case http.StatusUnauthorized:
+		return KindUnauthorized
+	case http.StatusForbidden:
+		return KindForbidden
 	default:
 		return KindUnknown
 	}
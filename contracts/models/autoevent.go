@@ -9,7 +9,19 @@ package models
 // https://app.swaggerhub.com/apis-docs/EdgeXFoundry1/core-metadata/2.x#/AutoEvent
 // Model fields are same as the DTOs documented by this swagger. Exceptions, if any, are noted below.
 type AutoEvent struct {
-	Frequency string
-	OnChange  bool
-	Resource  string
+	Frequency   string
+	OnChange    bool
+	Resource    string
+	Tag         string // reads all the device resources having the tag instead of Resource
+	Aggregation AutoEventAggregation
+}
+
+// AutoEventAggregation buffers the readings of an AutoEvent over Window and publishes
+// one event with the aggregated readings at the end of each window instead of one
+// event per read. An empty Window disables the aggregation.
+// Functions lists the aggregations to publish, any of min, max, mean, last and count.
+// All of them are published if empty.
+type AutoEventAggregation struct {
+	Window    string
+	Functions []string
 }
@@ -17,6 +17,7 @@ type DeviceProfile struct {
 	Manufacturer    string
 	Model           string
 	Labels          []string
+	Extends         []string
 	DeviceResources []DeviceResource
 	DeviceCommands  []ProfileResource
 	CoreCommands    []Command
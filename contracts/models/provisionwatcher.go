@@ -19,4 +19,12 @@ type ProvisionWatcher struct {
 	ServiceName         string
 	AdminState          AdminState
 	AutoEvents          []AutoEvent
+	// DeviceNameTemplate names the devices added by the watcher, such as
+	// meter-{protocol.serial}. The discovered name is used if it is empty.
+	DeviceNameTemplate string
+	// DeviceLabels are added to the labels of the devices added by the watcher
+	DeviceLabels []string
+	// IdentityKeys name the protocol properties identifying the devices discovered
+	// for the watcher, in addition to the keys declared by the driver
+	IdentityKeys []string
 }
@@ -158,8 +158,7 @@ func From(dr DeviceResource) ValueDescriptor {
 		DefaultValue: value.DefaultValue,
 		Formatting:   defaultValueDescriptorFormat,
 		Description:  dr.Description,
-		//FloatEncoding: value.FloatEncoding,
-		MediaType: value.MediaType,
+		MediaType:    value.MediaType,
 	}
 
 	return desc
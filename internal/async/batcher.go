@@ -0,0 +1,170 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/clients/interfaces"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+)
+
+// batchedEvent is an event queued in the EventBatcher with the callback
+// notified if the event cannot be delivered
+type batchedEvent struct {
+	event  dtos.Event
+	failed func(err errors.EdgeX)
+}
+
+func (b batchedEvent) fail(err errors.EdgeX) {
+	if b.failed != nil {
+		b.failed(err)
+	}
+}
+
+// EventBatcher accumulates events and sends them to core-data in a single request
+// once size events are queued or the oldest queued event waited for linger.
+// It falls back to one request per event if core-data does not support batches, detected
+// from the 404 or 405 status of the batch request, and for a batch rejected with a 400 or
+// 413 status. The events of a batch failing otherwise are reported as failed, not resent.
+type EventBatcher struct {
+	size        int
+	linger      time.Duration
+	events      chan batchedEvent
+	done        chan struct{}
+	ec          interfaces.EventClient
+	lc          logger.LoggingClient
+	unsupported int32
+}
+
+// NewEventBatcher creates an EventBatcher sending batches of up to size events
+func NewEventBatcher(size int, linger time.Duration, ec interfaces.EventClient, lc logger.LoggingClient) *EventBatcher {
+	return &EventBatcher{
+		size:   size,
+		linger: linger,
+		events: make(chan batchedEvent, size),
+		done:   make(chan struct{}),
+		ec:     ec,
+		lc:     lc,
+	}
+}
+
+// Run starts accumulating the events, the pending events are sent once ctx is done
+func (b *EventBatcher) Run(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(b.done)
+
+		batch := make([]batchedEvent, 0, b.size)
+		// the linger timer is only armed while events are pending
+		timer := time.NewTimer(b.linger)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				for len(b.events) > 0 {
+					batch = append(batch, <-b.events)
+				}
+				b.send(batch)
+				return
+			case event := <-b.events:
+				batch = append(batch, event)
+				if len(batch) == 1 {
+					timer.Reset(b.linger)
+				}
+				if len(batch) < b.size {
+					continue
+				}
+				if !timer.Stop() {
+					<-timer.C
+				}
+			case <-timer.C:
+			}
+			b.send(batch)
+			batch = make([]batchedEvent, 0, b.size)
+		}
+	}()
+}
+
+// Add queues the event for the next batch, failed is called if the event cannot be
+// delivered to core-data and may be nil
+func (b *EventBatcher) Add(event dtos.Event, failed func(err errors.EdgeX)) {
+	item := batchedEvent{event: event, failed: failed}
+	select {
+	case b.events <- item:
+	case <-b.done:
+		b.lc.Warn(fmt.Sprintf("SendEvent - event batcher stopped, dropped event of Device %s", event.DeviceName))
+		item.fail(errors.NewCommonEdgeX(errors.KindServiceUnavailable, "event batcher stopped", nil))
+	}
+}
+
+func (b *EventBatcher) send(batch []batchedEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	if atomic.LoadInt32(&b.unsupported) == 1 {
+		b.sendEach(batch)
+		return
+	}
+
+	reqs := make([]requests.AddEventRequest, len(batch))
+	for i, item := range batch {
+		reqs[i] = requests.AddEventRequest{
+			BaseRequest: common.NewBaseRequest(),
+			Event:       item.event,
+		}
+	}
+	ctx := context.WithValue(context.Background(), sdkCommon.CorrelationHeader, uuid.NewString())
+	responses, err := b.ec.AddBatch(ctx, reqs)
+	if err != nil {
+		kind := errors.Kind(err)
+		if kind == errors.KindEntityDoesNotExist || kind == errors.KindNotAllowed {
+			b.lc.Warn(fmt.Sprintf("SendEvent - core-data does not support event batches, sending events one by one: %v", err))
+			atomic.StoreInt32(&b.unsupported, 1)
+			b.sendEach(batch)
+			return
+		}
+		if kind == errors.KindContractInvalid || kind == errors.KindLimitExceeded {
+			// the batch was rejected before any event was stored, e.g. for its size,
+			// the events may still be accepted one by one
+			b.lc.Error(fmt.Sprintf("SendEvent - batch of %d events rejected, sending them one by one: %v", len(batch), err))
+			b.sendEach(batch)
+			return
+		}
+		// core-data may have stored some of the events, they are not sent again to
+		// avoid duplicates
+		b.lc.Error(fmt.Sprintf("SendEvent - failed to push a batch of %d events: %v", len(batch), err))
+		for _, item := range batch {
+			item.fail(err)
+		}
+		return
+	}
+
+	for i, res := range responses {
+		if res.StatusCode >= http.StatusMultipleChoices && i < len(batch) {
+			b.lc.Error(fmt.Sprintf("SendEvent - failed to push event of Device %s: %v", batch[i].event.DeviceName, res.Message))
+			batch[i].fail(errors.NewCommonEdgeX(errors.KindMapping(res.StatusCode), fmt.Sprintf("%v", res.Message), nil))
+		}
+	}
+	b.lc.Debug(fmt.Sprintf("SendEvent - pushed a batch of %d events to core data", len(batch)))
+}
+
+func (b *EventBatcher) sendEach(batch []batchedEvent) {
+	for _, item := range batch {
+		if err := sdkCommon.SendEvent(item.event, b.lc, b.ec); err != nil {
+			item.fail(err)
+		}
+	}
+}
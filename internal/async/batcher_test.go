@@ -0,0 +1,182 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	tdHttp "github.com/tuya/tuya-edge-driver-sdk-go/contracts/clients/http"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/clients/interfaces"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+)
+
+type eventClientMock struct {
+	interfaces.EventClient
+	mutex       sync.Mutex
+	unsupported bool
+	failure     errors.ErrKind // kind of the error of the next batch
+	batches     [][]string
+	singles     []string
+}
+
+func (ec *eventClientMock) Add(_ context.Context, req requests.AddEventRequest) (common.BaseWithIdResponse, errors.EdgeX) {
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+	ec.singles = append(ec.singles, req.Event.DeviceName)
+	return common.BaseWithIdResponse{}, nil
+}
+
+func (ec *eventClientMock) AddBatch(_ context.Context, reqs []requests.AddEventRequest) ([]common.BaseWithIdResponse, errors.EdgeX) {
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+	if ec.unsupported {
+		return nil, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, "request failed, status code: 404", nil)
+	}
+	if ec.failure != "" {
+		kind := ec.failure
+		ec.failure = ""
+		return nil, errors.NewCommonEdgeX(kind, "request failed", nil)
+	}
+	var names []string
+	for _, req := range reqs {
+		names = append(names, req.Event.DeviceName)
+	}
+	ec.batches = append(ec.batches, names)
+	return make([]common.BaseWithIdResponse, len(reqs)), nil
+}
+
+func (ec *eventClientMock) sent() ([][]string, []string) {
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+	return ec.batches, ec.singles
+}
+
+func TestEventBatcher(t *testing.T) {
+	ec := &eventClientMock{}
+	batcher := NewEventBatcher(3, 50*time.Millisecond, ec, logger.NewMockClient())
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	batcher.Run(ctx, wg)
+
+	for _, name := range []string{"d1", "d2", "d3", "d4"} {
+		batcher.Add(dtos.NewEvent("profile", name), nil)
+	}
+	// the first batch is sent once full, the second one after the linger time
+	assert.Eventually(t, func() bool {
+		batches, _ := ec.sent()
+		return len(batches) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	batcher.Add(dtos.NewEvent("profile", "d5"), nil)
+	cancel()
+	wg.Wait()
+
+	batches, singles := ec.sent()
+	assert.Equal(t, [][]string{{"d1", "d2", "d3"}, {"d4"}, {"d5"}}, batches, "pending events should be flushed on stop")
+	assert.Empty(t, singles)
+}
+
+func TestEventBatcherFallback(t *testing.T) {
+	ec := &eventClientMock{unsupported: true}
+	batcher := NewEventBatcher(2, time.Hour, ec, logger.NewMockClient())
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	batcher.Run(ctx, wg)
+
+	for _, name := range []string{"d1", "d2", "d3"} {
+		batcher.Add(dtos.NewEvent("profile", name), nil)
+	}
+	cancel()
+	wg.Wait()
+
+	batches, singles := ec.sent()
+	assert.Empty(t, batches)
+	assert.Equal(t, []string{"d1", "d2", "d3"}, singles)
+}
+
+func TestEventBatcherFallbackOnRejectedBatch(t *testing.T) {
+	ec := &eventClientMock{failure: errors.KindLimitExceeded}
+	batcher := NewEventBatcher(2, time.Hour, ec, logger.NewMockClient())
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	batcher.Run(ctx, wg)
+
+	for _, name := range []string{"d1", "d2", "d3", "d4"} {
+		batcher.Add(dtos.NewEvent("profile", name), nil)
+	}
+	cancel()
+	wg.Wait()
+
+	// the rejected batch is sent event by event, the next one as a batch again
+	batches, singles := ec.sent()
+	assert.Equal(t, [][]string{{"d3", "d4"}}, batches)
+	assert.Equal(t, []string{"d1", "d2"}, singles)
+}
+
+func TestEventBatcherFailure(t *testing.T) {
+	ec := &eventClientMock{failure: errors.KindServerError}
+	batcher := NewEventBatcher(2, time.Hour, ec, logger.NewMockClient())
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	batcher.Run(ctx, wg)
+
+	var failed []string
+	for _, name := range []string{"d1", "d2"} {
+		name := name
+		batcher.Add(dtos.NewEvent("profile", name), func(err errors.EdgeX) { failed = append(failed, name) })
+	}
+	cancel()
+	wg.Wait()
+
+	// core-data may have stored the events, they are reported instead of being resent
+	batches, singles := ec.sent()
+	assert.Empty(t, batches)
+	assert.Empty(t, singles)
+	assert.Equal(t, []string{"d1", "d2"}, failed)
+}
+
+func TestEventBatcherRouteNotFound(t *testing.T) {
+	var mutex sync.Mutex
+	var singles []string
+	// core-data without the batch route answers the plain text 404 of its router
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == contracts.ApiEventBatchRoute {
+			http.NotFound(w, r)
+			return
+		}
+		mutex.Lock()
+		singles = append(singles, path.Base(r.URL.Path))
+		mutex.Unlock()
+		w.Header().Set(contracts.ContentType, contracts.ContentTypeJSON)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(common.NewBaseWithIdResponse("", "", http.StatusCreated, "id"))
+	}))
+	defer server.Close()
+
+	batcher := NewEventBatcher(2, time.Hour, tdHttp.NewEventClient(server.URL), logger.NewMockClient())
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	batcher.Run(ctx, wg)
+
+	var failed []errors.EdgeX
+	for _, name := range []string{"d1", "d2", "d3"} {
+		batcher.Add(dtos.NewEvent("profile", name), func(err errors.EdgeX) { failed = append(failed, err) })
+	}
+	cancel()
+	wg.Wait()
+
+	assert.Empty(t, failed)
+	assert.Equal(t, []string{"d1", "d2", "d3"}, singles)
+}
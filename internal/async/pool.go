@@ -0,0 +1,141 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// Overflow policies of the WorkerPool, applied when the queue of a worker is full
+const (
+	OverflowBlock      = "block"
+	OverflowDropOldest = "drop-oldest"
+	OverflowDropNewest = "drop-newest"
+)
+
+// Handler processes the AsyncValues pushed by the driver
+type Handler func(acv *dsModels.AsyncValues)
+
+// DropCounts holds the number of AsyncValues discarded by the overflow policies
+type DropCounts struct {
+	Oldest uint64
+	Newest uint64
+}
+
+// WorkerPool processes AsyncValues with a fixed number of workers. The AsyncValues
+// of a device are always handled by the same worker, so they are processed in the
+// order they were submitted, and each worker has a bounded queue.
+//
+// Under the block policy a device filling the queue of its worker blocks the
+// submission of the AsyncValues of every device, including the devices of the other
+// workers, until the worker catches up: a slow device holds up all the others.
+type WorkerPool struct {
+	queues        []chan *dsModels.AsyncValues
+	policy        string
+	handler       Handler
+	lc            logger.LoggingClient
+	droppedOldest uint64
+	droppedNewest uint64
+}
+
+// NewWorkerPool creates a WorkerPool of the given number of workers, whose queues
+// share bufferSize AsyncValues: each one has bufferSize / workers slots, at least one.
+func NewWorkerPool(workers int, bufferSize int, policy string, handler Handler, lc logger.LoggingClient) (*WorkerPool, error) {
+	switch policy {
+	case "":
+		policy = OverflowBlock
+	case OverflowBlock, OverflowDropOldest, OverflowDropNewest:
+	default:
+		return nil, fmt.Errorf("unsupported overflow policy %s", policy)
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	queueSize := bufferSize / workers
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+
+	queues := make([]chan *dsModels.AsyncValues, workers)
+	for i := range queues {
+		queues[i] = make(chan *dsModels.AsyncValues, queueSize)
+	}
+	return &WorkerPool{
+		queues:  queues,
+		policy:  policy,
+		handler: handler,
+		lc:      lc,
+	}, nil
+}
+
+// Run starts the workers, they return once ctx is done
+func (p *WorkerPool) Run(ctx context.Context, wg *sync.WaitGroup) {
+	for _, queue := range p.queues {
+		wg.Add(1)
+		go func(queue chan *dsModels.AsyncValues) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case acv := <-queue:
+					p.handler(acv)
+				}
+			}
+		}(queue)
+	}
+}
+
+// Submit queues the AsyncValues to the worker of its device. If the queue is full,
+// it blocks until ctx is done or discards an AsyncValues depending on the policy.
+func (p *WorkerPool) Submit(ctx context.Context, acv *dsModels.AsyncValues) {
+	queue := p.queues[p.shard(acv.DeviceName)]
+	switch p.policy {
+	case OverflowDropNewest:
+		select {
+		case queue <- acv:
+		default:
+			atomic.AddUint64(&p.droppedNewest, 1)
+			p.lc.Warn(fmt.Sprintf("processAsyncResults - queue full, dropped readings of Device %s", acv.DeviceName))
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case queue <- acv:
+				return
+			default:
+			}
+			// the worker may have taken the oldest one meanwhile, retry in that case
+			select {
+			case dropped := <-queue:
+				atomic.AddUint64(&p.droppedOldest, 1)
+				p.lc.Warn(fmt.Sprintf("processAsyncResults - queue full, dropped oldest readings of Device %s", dropped.DeviceName))
+			default:
+			}
+		}
+	default:
+		select {
+		case queue <- acv:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// Dropped returns the number of AsyncValues discarded so far
+func (p *WorkerPool) Dropped() DropCounts {
+	return DropCounts{
+		Oldest: atomic.LoadUint64(&p.droppedOldest),
+		Newest: atomic.LoadUint64(&p.droppedNewest),
+	}
+}
+
+func (p *WorkerPool) shard(deviceName string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(deviceName))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
@@ -0,0 +1,113 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+func asyncValues(deviceName string, origin int64) *dsModels.AsyncValues {
+	return &dsModels.AsyncValues{
+		DeviceName:    deviceName,
+		CommandValues: []*dsModels.CommandValue{{DeviceResourceName: "r", Origin: origin}},
+	}
+}
+
+func TestWorkerPoolOrderPerDevice(t *testing.T) {
+	var mutex sync.Mutex
+	received := make(map[string][]int64)
+	var done sync.WaitGroup
+	handler := func(acv *dsModels.AsyncValues) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received[acv.DeviceName] = append(received[acv.DeviceName], acv.CommandValues[0].Origin)
+		done.Done()
+	}
+
+	pool, err := NewWorkerPool(4, 2, OverflowBlock, handler, logger.NewMockClient())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Run(ctx, &sync.WaitGroup{})
+
+	devices := []string{"device-1", "device-2", "device-3"}
+	done.Add(100 * len(devices))
+	for i := int64(0); i < 100; i++ {
+		for _, d := range devices {
+			pool.Submit(ctx, asyncValues(d, i))
+		}
+	}
+	done.Wait()
+
+	for _, d := range devices {
+		require.Len(t, received[d], 100)
+		for i, origin := range received[d] {
+			assert.Equal(t, int64(i), origin, "readings of %s out of order", d)
+		}
+	}
+	assert.Equal(t, DropCounts{}, pool.Dropped())
+}
+
+func TestWorkerPoolDropPolicies(t *testing.T) {
+	tests := []struct {
+		policy   string
+		dropped  DropCounts
+		expected []int64
+	}{
+		{OverflowDropNewest, DropCounts{Newest: 3}, []int64{0, 1}},
+		{OverflowDropOldest, DropCounts{Oldest: 3}, []int64{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			var received []int64
+			pool, err := NewWorkerPool(1, 2, tt.policy, func(acv *dsModels.AsyncValues) {
+				received = append(received, acv.CommandValues[0].Origin)
+			}, logger.NewMockClient())
+			require.NoError(t, err)
+
+			// the workers are not running, so the queue overflows
+			ctx := context.Background()
+			for i := int64(0); i < 5; i++ {
+				pool.Submit(ctx, asyncValues("device", i))
+			}
+			assert.Equal(t, tt.dropped, pool.Dropped())
+
+			for len(pool.queues[0]) > 0 {
+				pool.handler(<-pool.queues[0])
+			}
+			assert.Equal(t, tt.expected, received)
+		})
+	}
+}
+
+func TestNewWorkerPoolQueueSize(t *testing.T) {
+	tests := []struct {
+		workers    int
+		bufferSize int
+		queueSize  int
+	}{
+		{4, 10, 2},
+		{4, 2, 1},
+		{1, 8, 8},
+		{0, 8, 8},
+	}
+	for _, tt := range tests {
+		pool, err := NewWorkerPool(tt.workers, tt.bufferSize, OverflowBlock, nil, logger.NewMockClient())
+		require.NoError(t, err)
+		for _, queue := range pool.queues {
+			assert.Equal(t, tt.queueSize, cap(queue), "%d workers sharing %d slots", tt.workers, tt.bufferSize)
+		}
+	}
+}
+
+func TestNewWorkerPoolInvalidPolicy(t *testing.T) {
+	_, err := NewWorkerPool(1, 1, "drop-all", nil, logger.NewMockClient())
+	assert.Error(t, err, fmt.Sprintf("policy %s should be rejected", "drop-all"))
+}
@@ -0,0 +1,91 @@
+package async
+
+import (
+	"sync"
+
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// Rejections counts the rejected asynchronous readings by reason and reports
+// them to the ProtocolDriver if it implements AsyncRejectionHandler.
+type Rejections struct {
+	handler dsModels.AsyncRejectionHandler
+	mutex   sync.Mutex
+	counts  map[dsModels.RejectionReason]uint64
+}
+
+// NewRejections creates Rejections reporting to the given driver
+func NewRejections(driver dsModels.ProtocolDriver) *Rejections {
+	handler, _ := driver.(dsModels.AsyncRejectionHandler)
+	return &Rejections{
+		handler: handler,
+		counts:  make(map[dsModels.RejectionReason]uint64),
+	}
+}
+
+// Reject records the rejection of the given CommandValue
+func (r *Rejections) Reject(deviceName string, cv dsModels.CommandValue, reason dsModels.RejectionReason, err error) {
+	r.mutex.Lock()
+	r.counts[reason]++
+	r.mutex.Unlock()
+
+	if r.handler != nil {
+		r.handler.HandleAsyncRejection(dsModels.AsyncRejection{
+			DeviceName: deviceName,
+			Value:      cv,
+			Reason:     reason,
+			Err:        err,
+		})
+	}
+}
+
+// Counts returns the number of rejected readings by reason
+func (r *Rejections) Counts() map[string]uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	counts := make(map[string]uint64, len(r.counts))
+	for reason, count := range r.counts {
+		counts[string(reason)] = count
+	}
+	return counts
+}
+
+// EventValues are the CommandValues of an asynchronous event, as pushed by the
+// driver. A value is rejected at most once: the values already rejected while the
+// event is built, e.g. for a failed transformation, are not rejected again if the
+// event cannot be sent.
+type EventValues struct {
+	rejections *Rejections
+	deviceName string
+	values     []dsModels.CommandValue
+	rejected   []bool
+}
+
+// EventValues returns the EventValues of an event of the device
+func (r *Rejections) EventValues(deviceName string) *EventValues {
+	return &EventValues{rejections: r, deviceName: deviceName}
+}
+
+// Add adds the CommandValue pushed by the driver and returns its index
+func (e *EventValues) Add(cv dsModels.CommandValue) int {
+	e.values = append(e.values, cv)
+	e.rejected = append(e.rejected, false)
+	return len(e.values) - 1
+}
+
+// Reject rejects the CommandValue at index i, unless it is already rejected
+func (e *EventValues) Reject(i int, reason dsModels.RejectionReason, err error) {
+	if e.rejected[i] {
+		return
+	}
+	e.rejected[i] = true
+	e.rejections.Reject(e.deviceName, e.values[i], reason, err)
+}
+
+// SinkFailed rejects the CommandValues not rejected yet as RejectSinkFailed
+func (e *EventValues) SinkFailed(err error) {
+	for i := range e.values {
+		e.Reject(i, dsModels.RejectSinkFailed, err)
+	}
+}
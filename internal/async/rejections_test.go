@@ -0,0 +1,73 @@
+package async
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/mock"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+type rejectingDriverMock struct {
+	mock.DriverMock
+	rejections []dsModels.AsyncRejection
+}
+
+func (d *rejectingDriverMock) HandleAsyncRejection(rejection dsModels.AsyncRejection) {
+	d.rejections = append(d.rejections, rejection)
+}
+
+func TestRejections(t *testing.T) {
+	driver := &rejectingDriverMock{}
+	rejections := NewRejections(driver)
+
+	cv := dsModels.NewStringValue("temperature", 0, "abc")
+	err := errors.New("unknown resource")
+	rejections.Reject("device", *cv, dsModels.RejectUnknownResource, err)
+	rejections.Reject("device", *cv, dsModels.RejectUnknownResource, err)
+	rejections.Reject("other", *cv, dsModels.RejectUnknownDevice, err)
+
+	assert.Equal(t, map[string]uint64{
+		string(dsModels.RejectUnknownResource): 2,
+		string(dsModels.RejectUnknownDevice):   1,
+	}, rejections.Counts())
+
+	require.Len(t, driver.rejections, 3)
+	assert.Equal(t, "device", driver.rejections[0].DeviceName)
+	assert.Equal(t, "temperature", driver.rejections[0].Value.DeviceResourceName)
+	assert.Equal(t, dsModels.RejectUnknownDevice, driver.rejections[2].Reason)
+	assert.Equal(t, err, driver.rejections[2].Err)
+}
+
+func TestRejectionsWithoutHandler(t *testing.T) {
+	rejections := NewRejections(mock.DriverMock{})
+
+	cv := dsModels.NewStringValue("temperature", 0, "abc")
+	rejections.Reject("device", *cv, dsModels.RejectSinkFailed, errors.New("core-data unavailable"))
+	assert.Equal(t, uint64(1), rejections.Counts()[string(dsModels.RejectSinkFailed)])
+}
+
+func TestEventValuesRejectedOnce(t *testing.T) {
+	driver := &rejectingDriverMock{}
+	rejections := NewRejections(driver)
+	values := rejections.EventValues("device")
+
+	transformed := values.Add(*dsModels.NewStringValue("temperature", 0, "abc"))
+	values.Add(*dsModels.NewStringValue("humidity", 0, "def"))
+	values.Reject(transformed, dsModels.RejectTransformFailed, errors.New("overflow"))
+	values.SinkFailed(errors.New("core-data unavailable"))
+
+	assert.Equal(t, map[string]uint64{
+		string(dsModels.RejectTransformFailed): 1,
+		string(dsModels.RejectSinkFailed):      1,
+	}, rejections.Counts())
+
+	require.Len(t, driver.rejections, 2)
+	assert.Equal(t, "temperature", driver.rejections[0].Value.DeviceResourceName)
+	assert.Equal(t, dsModels.RejectTransformFailed, driver.rejections[0].Reason)
+	assert.Equal(t, "humidity", driver.rejections[1].Value.DeviceResourceName)
+	assert.Equal(t, dsModels.RejectSinkFailed, driver.rejections[1].Reason)
+}
@@ -0,0 +1,51 @@
+// Package auth authenticates the REST requests sent to the device service and applies
+// the auth policies of the kinds of route.
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"sort"
+
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// APIKeyHeader is the header carrying the static API key of a client
+const APIKeyHeader = "X-API-Key"
+
+type apiKeyAuthenticator struct {
+	clients []string
+	keys    map[string]string // key is client name
+}
+
+// NewAPIKeyAuthenticator returns an authenticator of the requests carrying one of the
+// static API keys in the X-API-Key header, the keys are given by client name.
+func NewAPIKeyAuthenticator(keys map[string]string) dsModels.Authenticator {
+	clients := make([]string, 0, len(keys))
+	for client := range keys {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+	return &apiKeyAuthenticator{clients: clients, keys: keys}
+}
+
+// Authenticate returns the name of the client of the API key of the request. All the
+// keys are compared in constant time, so the response time tells nothing of the keys.
+func (a *apiKeyAuthenticator) Authenticate(request *http.Request) (string, bool, error) {
+	key := request.Header.Get(APIKeyHeader)
+	if key == "" {
+		return "", false, nil
+	}
+
+	identity := ""
+	for _, client := range a.clients {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(a.keys[client])) == 1 {
+			identity = client
+		}
+	}
+	if identity == "" {
+		return "", false, errors.New("unknown API key")
+	}
+	return identity, true, nil
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// The kinds of route having their own auth policy
+const (
+	PublicRoutes   = "public"
+	ReadRoutes     = "read"
+	WriteRoutes    = "write"
+	CallbackRoutes = "callback"
+	AdminRoutes    = "admin"
+	DriverRoutes   = "driver"
+	// DefaultPolicy is the policy of the kinds of route without policy, except the public
+	// routes which stay open unless they have their own policy
+	DefaultPolicy = "default"
+)
+
+// The built-in authentication methods
+const (
+	APIKeyMethod = "apikey"
+	HMACMethod   = "hmac"
+	JWTMethod    = "jwt"
+)
+
+// Authorizer authenticates the requests to a kind of route with the methods of its
+// policy, and checks the authenticated client is allowed by the policy.
+type Authorizer struct {
+	authenticators map[string]dsModels.Authenticator // key is method
+	added          map[string]bool                   // methods added with AddAuthenticator
+	policies       map[string]common.AuthPolicy      // key is kind of route
+	mutex          sync.RWMutex
+}
+
+// NewAuthorizer returns an authorizer without methods nor policies, all the routes are open.
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{
+		authenticators: make(map[string]dsModels.Authenticator),
+		added:          make(map[string]bool),
+		policies:       make(map[string]common.AuthPolicy),
+	}
+}
+
+// Configure sets the policies of the configuration and the built-in methods having keys
+// in the configuration, the methods added with AddAuthenticator are kept. The methods
+// whose keys are invalid are not set, so the routes only accepting them are closed,
+// and the problems are returned as a single error.
+func (a *Authorizer) Configure(info common.AuthInfo) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var problems []string
+	for method := range a.authenticators {
+		if !a.added[method] {
+			delete(a.authenticators, method)
+		}
+	}
+	if len(info.APIKeys) > 0 {
+		a.setBuiltIn(APIKeyMethod, NewAPIKeyAuthenticator(info.APIKeys))
+	}
+	if len(info.HMACKeys) > 0 {
+		maxSkew := DefaultHMACMaxSkew
+		if info.HMACMaxSkew != "" {
+			d, err := time.ParseDuration(info.HMACMaxSkew)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("invalid HMACMaxSkew %s: %v", info.HMACMaxSkew, err))
+			} else {
+				maxSkew = d
+			}
+		}
+		a.setBuiltIn(HMACMethod, NewHMACAuthenticator(info.HMACKeys, maxSkew, info.HMACMaxBodySize))
+	}
+	if len(info.JWT.Keys) > 0 {
+		authenticator, err := NewJWTAuthenticator(info.JWT)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			a.setBuiltIn(JWTMethod, authenticator)
+		}
+	}
+
+	a.policies = make(map[string]common.AuthPolicy, len(info.Policies))
+	for kind, policy := range info.Policies {
+		a.policies[strings.ToLower(kind)] = policy
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (a *Authorizer) setBuiltIn(method string, authenticator dsModels.Authenticator) {
+	if !a.added[method] {
+		a.authenticators[method] = authenticator
+	}
+}
+
+// AddAuthenticator adds an authentication method, or replaces a built-in one, which
+// the policies can then accept.
+func (a *Authorizer) AddAuthenticator(method string, authenticator dsModels.Authenticator) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.authenticators[method] = authenticator
+	a.added[method] = true
+}
+
+// Authorize authenticates the request to the kind of route with the first method of
+// the policy the request has credentials of, and returns the identity of the client.
+// It returns a KindUnauthorized error if the request is not authenticated, a
+// KindLimitExceeded error if its body is too large to be authenticated, and a
+// KindForbidden error if the client is not allowed by the policy.
+func (a *Authorizer) Authorize(kind string, request *http.Request) (string, errors.EdgeX) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	policy, ok := a.policies[kind]
+	if !ok && kind != PublicRoutes {
+		policy = a.policies[DefaultPolicy]
+	}
+	if len(policy.Methods) == 0 {
+		return "", nil
+	}
+
+	for _, method := range policy.Methods {
+		authenticator, ok := a.authenticators[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		identity, ok, err := authenticator.Authenticate(request)
+		if err != nil {
+			kind := errors.KindUnauthorized
+			if errors.Kind(err) == errors.KindLimitExceeded {
+				kind = errors.KindLimitExceeded
+			}
+			return "", errors.NewCommonEdgeX(kind, fmt.Sprintf("%s authentication failed", method), err)
+		}
+		if !ok {
+			continue
+		}
+		if !allowed(policy.Clients, identity) {
+			errMsg := fmt.Sprintf("client %s is not allowed to access the %s routes", identity, kind)
+			return identity, errors.NewCommonEdgeX(errors.KindForbidden, errMsg, nil)
+		}
+		return identity, nil
+	}
+
+	errMsg := fmt.Sprintf("the %s routes require credentials of %s", kind, strings.Join(policy.Methods, ", "))
+	return "", errors.NewCommonEdgeX(errors.KindUnauthorized, errMsg, nil)
+}
+
+func allowed(clients []string, identity string) bool {
+	if len(clients) == 0 {
+		return true
+	}
+	for _, client := range clients {
+		if client == identity {
+			return true
+		}
+	}
+	return false
+}
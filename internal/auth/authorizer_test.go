@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+type headerAuthenticator struct{}
+
+func (headerAuthenticator) Authenticate(request *http.Request) (string, bool, error) {
+	identity := request.Header.Get("X-Client")
+	return identity, identity != "", nil
+}
+
+func testAuthInfo() common.AuthInfo {
+	return common.AuthInfo{
+		APIKeys:  map[string]string{"northbound": "key-1", "monitor": "key-2"},
+		HMACKeys: map[string]string{"gateway": "secret"},
+		Policies: map[string]common.AuthPolicy{
+			ReadRoutes:    {Methods: []string{APIKeyMethod, HMACMethod}},
+			WriteRoutes:   {Methods: []string{APIKeyMethod, HMACMethod}, Clients: []string{"northbound", "gateway"}},
+			DefaultPolicy: {Methods: []string{HMACMethod}},
+		},
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	authorizer := NewAuthorizer()
+	require.NoError(t, authorizer.Configure(testAuthInfo()))
+
+	withKey := func(key string) *http.Request {
+		request := httptest.NewRequest("GET", "/api/v2/device/name/meter/voltage", nil)
+		request.Header.Set(APIKeyHeader, key)
+		return request
+	}
+	signed := httptest.NewRequest("PUT", "/api/v2/device/name/meter/switch", nil)
+	require.NoError(t, SignRequest(signed, "gateway", "secret", time.Now()))
+
+	tests := []struct {
+		name     string
+		kind     string
+		request  *http.Request
+		identity string
+		errKind  errors.ErrKind
+	}{
+		{"read with key", ReadRoutes, withKey("key-2"), "monitor", ""},
+		{"read with unknown key", ReadRoutes, withKey("key-3"), "", errors.KindUnauthorized},
+		{"read without credentials", ReadRoutes, httptest.NewRequest("GET", "/", nil), "", errors.KindUnauthorized},
+		{"write by allowed client", WriteRoutes, withKey("key-1"), "northbound", ""},
+		{"write by other client", WriteRoutes, withKey("key-2"), "monitor", errors.KindForbidden},
+		{"write signed", WriteRoutes, signed, "gateway", ""},
+		{"public is open under the default policy", PublicRoutes, httptest.NewRequest("GET", "/", nil), "", ""},
+		{"default policy", AdminRoutes, withKey("key-1"), "", errors.KindUnauthorized},
+		{"callback under the default policy", CallbackRoutes, httptest.NewRequest("PUT", "/", nil), "", errors.KindUnauthorized},
+		{"callback signed", CallbackRoutes, signed, "gateway", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := authorizer.Authorize(tt.kind, tt.request)
+			assert.Equal(t, tt.identity, identity)
+			if tt.errKind == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Equal(t, tt.errKind, errors.Kind(err))
+			}
+		})
+	}
+}
+
+func TestAuthorizer_PublicPolicy(t *testing.T) {
+	info := testAuthInfo()
+	info.Policies[PublicRoutes] = common.AuthPolicy{Methods: []string{APIKeyMethod}}
+	authorizer := NewAuthorizer()
+	require.NoError(t, authorizer.Configure(info))
+
+	_, err := authorizer.Authorize(PublicRoutes, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, errors.KindUnauthorized, errors.Kind(err))
+}
+
+func TestAuthorizer_BodyTooLarge(t *testing.T) {
+	info := testAuthInfo()
+	info.HMACMaxBodySize = 8
+	authorizer := NewAuthorizer()
+	require.NoError(t, authorizer.Configure(info))
+
+	request := httptest.NewRequest("PUT", "/api/v2/device/name/meter/switch", strings.NewReader(`{"switch":true}`))
+	require.NoError(t, SignRequest(request, "gateway", "secret", time.Now()))
+	_, err := authorizer.Authorize(WriteRoutes, request)
+	require.Error(t, err)
+	assert.Equal(t, errors.KindLimitExceeded, errors.Kind(err))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, err.Code())
+}
+
+func TestAuthorizer_OpenWithoutPolicy(t *testing.T) {
+	authorizer := NewAuthorizer()
+	identity, err := authorizer.Authorize(WriteRoutes, httptest.NewRequest("PUT", "/", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, identity)
+}
+
+func TestAuthorizer_AddAuthenticator(t *testing.T) {
+	authorizer := NewAuthorizer()
+	authorizer.AddAuthenticator("header", headerAuthenticator{})
+	info := testAuthInfo()
+	info.Policies[DriverRoutes] = common.AuthPolicy{Methods: []string{"header"}}
+	require.NoError(t, authorizer.Configure(info))
+
+	request := httptest.NewRequest("GET", "/api/v2/driver/route", nil)
+	request.Header.Set("X-Client", "plc")
+	identity, err := authorizer.Authorize(DriverRoutes, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "plc", identity)
+}
+
+func TestAuthorizer_InvalidConfiguration(t *testing.T) {
+	authorizer := NewAuthorizer()
+	info := testAuthInfo()
+	info.JWT.Keys = map[string]common.JWTKeyInfo{"main": {Algorithm: "HS256"}}
+	info.Policies[AdminRoutes] = common.AuthPolicy{Methods: []string{JWTMethod}}
+	assert.Error(t, authorizer.Configure(info))
+
+	request := httptest.NewRequest("GET", "/api/v2/config", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	_, err := authorizer.Authorize(AdminRoutes, request)
+	assert.Equal(t, errors.KindUnauthorized, errors.Kind(err))
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	edgexErr "github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+const (
+	// HMACScheme is the scheme of the Authorization header of the signed requests, the
+	// credentials are the key id and the Base64 encoded signature separated by a colon
+	HMACScheme = "HMAC-SHA256"
+	// TimestampHeader is the header carrying the Unix time a request is signed at
+	TimestampHeader = "X-Timestamp"
+	// DefaultHMACMaxSkew is the default maximum difference between the timestamp of a
+	// signed request and the time it is received
+	DefaultHMACMaxSkew = 5 * time.Minute
+	// DefaultHMACMaxBodySize is the default maximum size in bytes of the body of a signed
+	// request, which is read before the signature is checked
+	DefaultHMACMaxBodySize = 1 << 20
+)
+
+type hmacAuthenticator struct {
+	keys        map[string]string // key is key id
+	maxSkew     time.Duration
+	maxBodySize int64
+	now         func() time.Time
+}
+
+// NewHMACAuthenticator returns an authenticator of the requests signed with one of the
+// shared secrets, given by key id, whose timestamp differs from the current time by at
+// most maxSkew and whose body has at most maxBodySize bytes. A larger body is rejected
+// with a KindLimitExceeded error.
+func NewHMACAuthenticator(keys map[string]string, maxSkew time.Duration, maxBodySize int64) dsModels.Authenticator {
+	if maxSkew <= 0 {
+		maxSkew = DefaultHMACMaxSkew
+	}
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultHMACMaxBodySize
+	}
+	return &hmacAuthenticator{keys: keys, maxSkew: maxSkew, maxBodySize: maxBodySize, now: time.Now}
+}
+
+// Authenticate returns the key id of the signature of the request
+func (a *hmacAuthenticator) Authenticate(request *http.Request) (string, bool, error) {
+	credentials, ok := authorization(request, HMACScheme)
+	if !ok {
+		return "", false, nil
+	}
+	parts := strings.SplitN(credentials, ":", 2)
+	if len(parts) != 2 {
+		return "", false, errors.New("malformed HMAC credentials")
+	}
+	keyID, signature := parts[0], parts[1]
+	secret, ok := a.keys[keyID]
+	if !ok {
+		return "", false, fmt.Errorf("unknown HMAC key %s", keyID)
+	}
+
+	timestamp := request.Header.Get(TimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid %s header %q", TimestampHeader, timestamp)
+	}
+	if skew := a.now().Sub(time.Unix(seconds, 0)); skew > a.maxSkew || -skew > a.maxSkew {
+		return "", false, fmt.Errorf("request signed at %s is out of the accepted clock skew", time.Unix(seconds, 0).UTC())
+	}
+
+	expected, err := sign(request, secret, timestamp, a.maxBodySize)
+	if err != nil {
+		return "", false, err
+	}
+	actual, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(actual, expected) {
+		return "", false, errors.New("invalid HMAC signature")
+	}
+	return keyID, true, nil
+}
+
+// SignRequest signs the request with the secret of the key id at the given time, setting
+// its Authorization and X-Timestamp headers. The signature covers the method, the
+// path and query, the timestamp and the SHA-256 digest of the body, each on its own line.
+func SignRequest(request *http.Request, keyID string, secret string, at time.Time) error {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	signature, err := sign(request, secret, timestamp, 0)
+	if err != nil {
+		return err
+	}
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set("Authorization", HMACScheme+" "+keyID+":"+base64.StdEncoding.EncodeToString(signature))
+	return nil
+}
+
+// sign returns the signature of the request, the body is read and restored for the handler.
+// A body larger than maxBodySize bytes, if positive, is rejected with a KindLimitExceeded error.
+func sign(request *http.Request, secret string, timestamp string, maxBodySize int64) ([]byte, error) {
+	var body []byte
+	if request.Body != nil {
+		reader := io.Reader(request.Body)
+		if maxBodySize > 0 {
+			reader = io.LimitReader(request.Body, maxBodySize+1)
+		}
+		var err error
+		body, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the request body: %v", err)
+		}
+		if maxBodySize > 0 && int64(len(body)) > maxBodySize {
+			errMsg := fmt.Sprintf("the body of a signed request is limited to %d bytes", maxBodySize)
+			return nil, edgexErr.NewCommonEdgeX(edgexErr.KindLimitExceeded, errMsg, nil)
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	digest := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{request.Method, request.URL.RequestURI(), timestamp, hex.EncodeToString(digest[:])}, "\n")))
+	return mac.Sum(nil), nil
+}
+
+// authorization returns the credentials of the Authorization header of the request
+// if it uses the scheme
+func authorization(request *http.Request, scheme string) (string, bool) {
+	parts := strings.SplitN(request.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], scheme) {
+		return "", false
+	}
+	return strings.TrimSpace(parts[1]), true
+}
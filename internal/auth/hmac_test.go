@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+)
+
+func TestHMACAuthenticator(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	authenticator := NewHMACAuthenticator(map[string]string{"gateway": "secret"}, time.Minute, 0).(*hmacAuthenticator)
+	authenticator.now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		keyID    string
+		secret   string
+		at       time.Time
+		tamper   func(body string) string
+		identity string
+		ok       bool
+		err      bool
+	}{
+		{"valid", "gateway", "secret", now, nil, "gateway", true, false},
+		{"within skew", "gateway", "secret", now.Add(-50 * time.Second), nil, "gateway", true, false},
+		{"out of skew", "gateway", "secret", now.Add(-2 * time.Minute), nil, "", false, true},
+		{"wrong secret", "gateway", "other", now, nil, "", false, true},
+		{"unknown key", "other", "secret", now, nil, "", false, true},
+		{"tampered body", "gateway", "secret", now, func(body string) string { return strings.Replace(body, "1", "2", 1) }, "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"switch":1}`
+			request := httptest.NewRequest("PUT", "/api/v2/device/name/meter/switch?x=1", strings.NewReader(body))
+			require.NoError(t, SignRequest(request, tt.keyID, tt.secret, tt.at))
+			if tt.tamper != nil {
+				request.Body = ioutil.NopCloser(strings.NewReader(tt.tamper(body)))
+			}
+
+			identity, ok, err := authenticator.Authenticate(request)
+			assert.Equal(t, tt.identity, identity)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err != nil)
+		})
+	}
+}
+
+func TestHMACAuthenticator_RestoresBody(t *testing.T) {
+	authenticator := NewHMACAuthenticator(map[string]string{"gateway": "secret"}, 0, 0)
+	request := httptest.NewRequest("PUT", "/api/v2/device/name/meter/switch", strings.NewReader(`{"switch":true}`))
+	require.NoError(t, SignRequest(request, "gateway", "secret", time.Now()))
+
+	_, ok, err := authenticator.Authenticate(request)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	body, err := ioutil.ReadAll(request.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"switch":true}`, string(body))
+}
+
+func TestHMACAuthenticator_NoCredentials(t *testing.T) {
+	authenticator := NewHMACAuthenticator(map[string]string{"gateway": "secret"}, 0, 0)
+	request := httptest.NewRequest("GET", "/api/v2/ping", nil)
+	request.Header.Set("Authorization", "Bearer token")
+
+	_, ok, err := authenticator.Authenticate(request)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestHMACAuthenticator_BodyTooLarge(t *testing.T) {
+	authenticator := NewHMACAuthenticator(map[string]string{"gateway": "secret"}, 0, 8)
+	request := httptest.NewRequest("PUT", "/api/v2/device/name/meter/switch", strings.NewReader(`{"switch":true}`))
+	require.NoError(t, SignRequest(request, "gateway", "secret", time.Now()))
+
+	_, ok, err := authenticator.Authenticate(request)
+	assert.False(t, ok)
+	assert.Equal(t, errors.KindLimitExceeded, errors.Kind(err))
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+const (
+	// BearerScheme is the scheme of the Authorization header carrying a JSON Web Token
+	BearerScheme = "Bearer"
+	// JWTLeeway is the clock skew tolerated when checking the expiration and the not
+	// before time of the tokens
+	JWTLeeway = time.Minute
+
+	algorithmHS256 = "HS256"
+	algorithmRS256 = "RS256"
+	algorithmES256 = "ES256"
+)
+
+// jwtKey verifies the signatures of the tokens of an algorithm
+type jwtKey struct {
+	algorithm string
+	secret    []byte
+	rsaKey    *rsa.PublicKey
+	ecdsaKey  *ecdsa.PublicKey
+}
+
+type jwtAuthenticator struct {
+	keys     map[string]jwtKey // key is key id
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTAuthenticator returns an authenticator of the requests carrying a JSON Web Token
+// as bearer token, signed by one of the keys of the configuration. It returns an error
+// if a key is invalid or its public key file cannot be read.
+func NewJWTAuthenticator(info common.JWTInfo) (dsModels.Authenticator, error) {
+	keys := make(map[string]jwtKey, len(info.Keys))
+	for kid, keyInfo := range info.Keys {
+		key, err := newJWTKey(keyInfo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT key %s: %v", kid, err)
+		}
+		keys[kid] = key
+	}
+	return &jwtAuthenticator{keys: keys, issuer: info.Issuer, audience: info.Audience, now: time.Now}, nil
+}
+
+func newJWTKey(info common.JWTKeyInfo) (jwtKey, error) {
+	key := jwtKey{algorithm: info.Algorithm}
+	switch info.Algorithm {
+	case algorithmHS256:
+		if info.Secret == "" {
+			return key, errors.New("HS256 requires a secret")
+		}
+		key.secret = []byte(info.Secret)
+		return key, nil
+	case algorithmRS256, algorithmES256:
+		publicKey, err := readPublicKey(info.PublicKeyFile)
+		if err != nil {
+			return key, err
+		}
+		var ok bool
+		if info.Algorithm == algorithmRS256 {
+			key.rsaKey, ok = publicKey.(*rsa.PublicKey)
+		} else {
+			key.ecdsaKey, ok = publicKey.(*ecdsa.PublicKey)
+		}
+		if !ok {
+			return key, fmt.Errorf("the public key of %s is not a %s key", info.PublicKeyFile, info.Algorithm)
+		}
+		return key, nil
+	default:
+		return key, fmt.Errorf("unsupported algorithm %q", info.Algorithm)
+	}
+}
+
+// readPublicKey reads the public key of a PEM file holding a public key or a certificate
+func readPublicKey(file string) (interface{}, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", file)
+	}
+	if block.Type == "CERTIFICATE" {
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+// Authenticate returns the subject of the token of the request. The algorithm of the
+// token must be the one of its key, the tokens signed with another algorithm or not
+// signed are rejected.
+func (a *jwtAuthenticator) Authenticate(request *http.Request) (string, bool, error) {
+	token, ok := authorization(request, BearerScheme)
+	if !ok {
+		return "", false, nil
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", false, errors.New("malformed JSON Web Token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", false, fmt.Errorf("malformed JSON Web Token header: %v", err)
+	}
+	key, err := a.key(header.KeyID)
+	if err != nil {
+		return "", false, err
+	}
+	if header.Algorithm != key.algorithm {
+		return "", false, fmt.Errorf("JSON Web Token signed with %q instead of %s", header.Algorithm, key.algorithm)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", false, fmt.Errorf("malformed JSON Web Token signature: %v", err)
+	}
+	if !key.verify(parts[0]+"."+parts[1], signature) {
+		return "", false, errors.New("invalid JSON Web Token signature")
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", false, fmt.Errorf("malformed JSON Web Token claims: %v", err)
+	}
+	if err := a.checkClaims(claims); err != nil {
+		return "", false, err
+	}
+	return claims.Subject, true, nil
+}
+
+func (a *jwtAuthenticator) key(kid string) (jwtKey, error) {
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, nil
+		}
+	}
+	key, ok := a.keys[kid]
+	if !ok {
+		return key, fmt.Errorf("unknown JSON Web Token key %q", kid)
+	}
+	return key, nil
+}
+
+func (a *jwtAuthenticator) checkClaims(claims jwtClaims) error {
+	now := a.now()
+	if claims.ExpiresAt != nil && now.After(time.Unix(*claims.ExpiresAt, 0).Add(JWTLeeway)) {
+		return errors.New("expired JSON Web Token")
+	}
+	if claims.NotBefore != nil && now.Add(JWTLeeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return errors.New("JSON Web Token not valid yet")
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return fmt.Errorf("JSON Web Token issued by %q", claims.Issuer)
+	}
+	if a.audience != "" && !hasAudience(claims.Audience, a.audience) {
+		return fmt.Errorf("JSON Web Token not intended for %s", a.audience)
+	}
+	if claims.Subject == "" {
+		return errors.New("JSON Web Token without subject")
+	}
+	return nil
+}
+
+// hasAudience tells whether the aud claim, a string or an array of strings, has the audience
+func hasAudience(claim json.RawMessage, audience string) bool {
+	var audiences []string
+	if err := json.Unmarshal(claim, &audiences); err != nil {
+		var single string
+		if err := json.Unmarshal(claim, &single); err != nil {
+			return false
+		}
+		audiences = []string{single}
+	}
+	for _, aud := range audiences {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+func (k jwtKey) verify(signingInput string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch k.algorithm {
+	case algorithmHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(signature, mac.Sum(nil))
+	case algorithmRS256:
+		return rsa.VerifyPKCS1v15(k.rsaKey, crypto.SHA256, digest[:], signature) == nil
+	case algorithmES256:
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(k.ecdsaKey, digest[:], r, s)
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// token returns a JSON Web Token signed by the sign function
+func token(t *testing.T, header map[string]interface{}, claims map[string]interface{}, sign func(input string) []byte) string {
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign(input))
+}
+
+func hs256(secret string) func(string) []byte {
+	return func(input string) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(input))
+		return mac.Sum(nil)
+	}
+}
+
+func writePublicKey(t *testing.T, publicKey interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return file
+}
+
+func authenticate(t *testing.T, info common.JWTInfo, token string) (string, bool, error) {
+	authenticator, err := NewJWTAuthenticator(info)
+	require.NoError(t, err)
+	request := httptest.NewRequest("GET", "/api/v2/device/name/meter/voltage", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	return authenticator.Authenticate(request)
+}
+
+func TestJWTAuthenticator_HS256(t *testing.T) {
+	info := common.JWTInfo{
+		Keys:     map[string]common.JWTKeyInfo{"main": {Algorithm: "HS256", Secret: "secret"}},
+		Issuer:   "edge-auth",
+		Audience: "device-simple",
+	}
+	now := time.Now().Unix()
+	valid := map[string]interface{}{"sub": "northbound", "iss": "edge-auth", "aud": []string{"device-simple"}, "exp": now + 60}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := make(map[string]interface{})
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		header   map[string]interface{}
+		claims   map[string]interface{}
+		secret   string
+		identity string
+	}{
+		{"valid", map[string]interface{}{"alg": "HS256", "kid": "main"}, valid, "secret", "northbound"},
+		{"single key without kid", map[string]interface{}{"alg": "HS256"}, with("aud", "device-simple"), "secret", "northbound"},
+		{"wrong secret", map[string]interface{}{"alg": "HS256", "kid": "main"}, valid, "other", ""},
+		{"unknown kid", map[string]interface{}{"alg": "HS256", "kid": "other"}, valid, "secret", ""},
+		{"other algorithm", map[string]interface{}{"alg": "none", "kid": "main"}, valid, "secret", ""},
+		{"expired", map[string]interface{}{"alg": "HS256", "kid": "main"}, with("exp", now-120), "secret", ""},
+		{"not valid yet", map[string]interface{}{"alg": "HS256", "kid": "main"}, with("nbf", now+120), "secret", ""},
+		{"other issuer", map[string]interface{}{"alg": "HS256", "kid": "main"}, with("iss", "other"), "secret", ""},
+		{"other audience", map[string]interface{}{"alg": "HS256", "kid": "main"}, with("aud", "other"), "secret", ""},
+		{"no subject", map[string]interface{}{"alg": "HS256", "kid": "main"}, with("sub", ""), "secret", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, ok, err := authenticate(t, info, token(t, tt.header, tt.claims, hs256(tt.secret)))
+			assert.Equal(t, tt.identity, identity)
+			assert.Equal(t, tt.identity != "", ok)
+			assert.Equal(t, tt.identity == "", err != nil)
+		})
+	}
+}
+
+func TestJWTAuthenticator_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	info := common.JWTInfo{Keys: map[string]common.JWTKeyInfo{"rsa": {Algorithm: "RS256", PublicKeyFile: writePublicKey(t, &key.PublicKey)}}}
+
+	signed := token(t, map[string]interface{}{"alg": "RS256", "kid": "rsa"}, map[string]interface{}{"sub": "northbound"}, func(input string) []byte {
+		digest := sha256.Sum256([]byte(input))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signature
+	})
+	identity, ok, err := authenticate(t, info, signed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "northbound", identity)
+
+	// a token signed with the public key as HS256 secret is rejected
+	publicPEM, err := ioutil.ReadFile(info.Keys["rsa"].PublicKeyFile)
+	require.NoError(t, err)
+	forged := token(t, map[string]interface{}{"alg": "HS256", "kid": "rsa"}, map[string]interface{}{"sub": "admin"}, hs256(string(publicPEM)))
+	_, ok, err = authenticate(t, info, forged)
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestJWTAuthenticator_ES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	info := common.JWTInfo{Keys: map[string]common.JWTKeyInfo{"ec": {Algorithm: "ES256", PublicKeyFile: writePublicKey(t, &key.PublicKey)}}}
+
+	signed := token(t, map[string]interface{}{"alg": "ES256", "kid": "ec"}, map[string]interface{}{"sub": "northbound"}, func(input string) []byte {
+		digest := sha256.Sum256([]byte(input))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature
+	})
+	identity, ok, err := authenticate(t, info, signed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "northbound", identity)
+}
+
+func TestNewJWTAuthenticator_InvalidKeys(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  common.JWTKeyInfo
+	}{
+		{"HS256 without secret", common.JWTKeyInfo{Algorithm: "HS256"}},
+		{"unsupported algorithm", common.JWTKeyInfo{Algorithm: "PS256"}},
+		{"missing file", common.JWTKeyInfo{Algorithm: "RS256", PublicKeyFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"key of another algorithm", common.JWTKeyInfo{Algorithm: "RS256", PublicKeyFile: writePublicKey(t, &key.PublicKey)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTAuthenticator(common.JWTInfo{Keys: map[string]common.JWTKeyInfo{"key": tt.key}})
+			assert.Error(t, err)
+		})
+	}
+}
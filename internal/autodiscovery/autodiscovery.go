@@ -16,6 +16,7 @@ import (
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/startup"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
 )
 
@@ -49,13 +50,21 @@ func BootstrapHandler(
 			defer wg.Done()
 
 			lc.Info(fmt.Sprintf("Starting auto-discovery with duration %v", duration))
-			DiscoveryWrapper(discovery, lc)
+			discover := func() {
+				// auto-discovery is paused while the device service is locked
+				if ds := container.DeviceServiceFrom(dic.Get); ds.AdminState == models.Locked {
+					lc.Debug("AutoDiscovery skipped for locked device service")
+					return
+				}
+				DiscoveryWrapper(discovery, lc)
+			}
+			discover()
 			for {
 				select {
 				case <-ctx.Done():
 					return
 				case <-time.After(duration):
-					DiscoveryWrapper(discovery, lc)
+					discover()
 				}
 			}
 		}()
@@ -0,0 +1,49 @@
+package autodiscovery
+
+import (
+	"context"
+
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// ProcessBatches processes the batches of discovered devices the driver sends on deviceCh,
+// one at a time, until ctx is done. A discovery job completes only once the batches the
+// driver sent during the discovery are processed, so their results are attributed to it.
+func ProcessBatches(ctx context.Context, deviceCh <-chan []dsModels.DiscoveredDevice, process func([]dsModels.DiscoveredDevice)) {
+	drain := make(chan chan struct{})
+	jobs.setDrain(func() {
+		done := make(chan struct{})
+		select {
+		case drain <- done:
+			<-done
+		case <-ctx.Done():
+		}
+	})
+	defer jobs.setDrain(nil)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case devices := <-deviceCh:
+			processBatch(devices, process)
+		case done := <-drain:
+			// the driver returned from the discovery, so the batches it sent are either
+			// processed or still buffered in the channel
+			for drained := false; !drained; {
+				select {
+				case devices := <-deviceCh:
+					processBatch(devices, process)
+				default:
+					drained = true
+				}
+			}
+			close(done)
+		}
+	}
+}
+
+func processBatch(devices []dsModels.DiscoveredDevice, process func([]dsModels.DiscoveredDevice)) {
+	process(devices)
+	jobs.batchProcessed()
+}
@@ -7,35 +7,17 @@
 package autodiscovery
 
 import (
-	"fmt"
-	"sync"
-
 	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
 	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
 
-type discoveryLocker struct {
-	busy bool
-	mux  sync.Mutex
-}
-
-var locker discoveryLocker
-
+// DiscoveryWrapper runs a discovery job without options and waits for it to finish.
+// It does nothing if another discovery job is currently running.
 func DiscoveryWrapper(discovery dsModels.ProtocolDiscovery, lc logger.LoggingClient) {
-	locker.mux.Lock()
-	if locker.busy {
-		lc.Info("another device discovery process is currently running")
-		locker.mux.Unlock()
+	job, ctx, err := jobs.start(nil)
+	if err != nil {
+		lc.Info(err.Message())
 		return
 	}
-	locker.busy = true
-	locker.mux.Unlock()
-
-	lc.Debug(fmt.Sprintf("protocol discovery triggered"))
-	discovery.Discover()
-
-	// ReleaseLock
-	locker.mux.Lock()
-	locker.busy = false
-	locker.mux.Unlock()
+	runJob(ctx, job, discovery, lc)
 }
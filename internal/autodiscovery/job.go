@@ -0,0 +1,306 @@
+package autodiscovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// JobStatus is the state of a discovery job
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "RUNNING"
+	JobCompleted JobStatus = "COMPLETED"
+	JobFailed    JobStatus = "FAILED"
+	JobCancelled JobStatus = "CANCELLED"
+)
+
+// maxFinishedJobs is the number of finished jobs kept for their results
+const maxFinishedJobs = 20
+
+// legacyJobTimeout is how long a job of a driver not implementing ContextDiscovery
+// waits for the driver to send the discovered devices. Such drivers discover in the
+// background, their job completes once the first batch of devices they send is
+// processed or after the timeout.
+var legacyJobTimeout = time.Minute
+
+// Job is a discovery run. The devices sent by the driver while the job is running
+// are attributed to it, the results name the devices as discovered by the driver.
+type Job struct {
+	Id       string
+	Status   JobStatus
+	Options  map[string]string
+	Started  int64
+	Finished int64
+	Error    string
+	Found    []string
+	Added    []string
+	// Updated holds the discovered devices whose protocol properties updated an
+	// existing device having the same identity
+	Updated []string
+	// Rejected maps the rejected device names to the reason
+	Rejected map[string]string
+
+	cancel context.CancelFunc
+	batch  chan struct{}
+}
+
+func (j *Job) copy() Job {
+	c := *j
+	c.Found = append([]string(nil), j.Found...)
+	c.Added = append([]string(nil), j.Added...)
+	c.Updated = append([]string(nil), j.Updated...)
+	c.Rejected = make(map[string]string, len(j.Rejected))
+	for name, reason := range j.Rejected {
+		c.Rejected[name] = reason
+	}
+	c.cancel = nil
+	c.batch = nil
+	return c
+}
+
+type jobStore struct {
+	mutex   sync.Mutex
+	jobs    map[string]*Job
+	order   []string
+	running *Job
+	// drain waits for the batches sent by the driver to be processed, it is nil if
+	// the batches are not processed by ProcessBatches
+	drain func()
+}
+
+var jobs = jobStore{jobs: make(map[string]*Job)}
+
+// StartJob starts a discovery job in the background and returns it. Only one job
+// runs at a time, StartJob fails with KindStatusConflict while another one runs.
+func StartJob(discovery dsModels.ProtocolDiscovery, options map[string]string, lc logger.LoggingClient) (Job, errors.EdgeX) {
+	job, ctx, err := jobs.start(options)
+	if err != nil {
+		return Job{}, err
+	}
+	go runJob(ctx, job, discovery, lc)
+	return jobs.snapshot(job), nil
+}
+
+// JobById returns the discovery job with the given id
+func JobById(id string) (Job, bool) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	job, ok := jobs.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return job.copy(), true
+}
+
+// AllJobs returns the running and the recently finished discovery jobs, oldest first
+func AllJobs() []Job {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	all := make([]Job, 0, len(jobs.order))
+	for _, id := range jobs.order {
+		all = append(all, jobs.jobs[id].copy())
+	}
+	return all
+}
+
+// CancelJob cancels the running discovery job with the given id. Drivers not
+// implementing ContextDiscovery keep on discovering, but their results are no
+// longer attributed to the job.
+func CancelJob(id string) errors.EdgeX {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	job, ok := jobs.jobs[id]
+	if !ok {
+		return errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("discovery job %s not found", id), nil)
+	}
+	if job.Status != JobRunning {
+		return errors.NewCommonEdgeX(errors.KindStatusConflict, fmt.Sprintf("discovery job %s is %s", id, job.Status), nil)
+	}
+	job.cancel()
+	jobs.finish(job, JobCancelled, nil)
+	return nil
+}
+
+// CancelRunningJob cancels the running discovery job, if any, and returns it
+func CancelRunningJob() (Job, bool) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	job := jobs.running
+	if job == nil {
+		return Job{}, false
+	}
+	job.cancel()
+	jobs.finish(job, JobCancelled, nil)
+	return job.copy(), true
+}
+
+// RecordFound attributes a device sent by the driver to the running job
+func RecordFound(name string) {
+	jobs.record(func(job *Job) {
+		job.Found = append(job.Found, name)
+	})
+}
+
+// RecordAdded records that the device of the running job was added
+func RecordAdded(name string) {
+	jobs.record(func(job *Job) {
+		job.Added = append(job.Added, name)
+	})
+}
+
+// RecordUpdated records that an existing device was updated for a device of the running job
+func RecordUpdated(name string) {
+	jobs.record(func(job *Job) {
+		job.Updated = append(job.Updated, name)
+	})
+}
+
+// RecordRejected records that the device of the running job was not added
+func RecordRejected(name string, reason string) {
+	jobs.record(func(job *Job) {
+		job.Rejected[name] = reason
+	})
+}
+
+func runJob(ctx context.Context, job *Job, discovery dsModels.ProtocolDiscovery, lc logger.LoggingClient) {
+	lc.Debug(fmt.Sprintf("protocol discovery job %s triggered", job.Id))
+
+	var err error
+	if cd, ok := discovery.(dsModels.ContextDiscovery); ok {
+		err = cd.DiscoverContext(ctx, job.Options)
+		jobs.drainBatches()
+	} else {
+		if len(job.Options) > 0 {
+			lc.Warn(fmt.Sprintf("discovery job %s: options ignored, ProtocolDiscovery does not implement ContextDiscovery", job.Id))
+		}
+		discovery.Discover()
+		select {
+		case <-job.batch:
+		case <-ctx.Done():
+		case <-time.After(legacyJobTimeout):
+			lc.Warn(fmt.Sprintf("discovery job %s: no device received after %s", job.Id, legacyJobTimeout))
+		}
+	}
+
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+	if job.Status != JobRunning {
+		// cancelled meanwhile
+		return
+	}
+	job.cancel()
+	if err != nil {
+		lc.Error(fmt.Sprintf("discovery job %s failed: %v", job.Id, err))
+		jobs.finish(job, JobFailed, err)
+		return
+	}
+	jobs.finish(job, JobCompleted, nil)
+}
+
+func (s *jobStore) start(options map[string]string) (*Job, context.Context, errors.EdgeX) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running != nil {
+		return nil, nil, errors.NewCommonEdgeX(errors.KindStatusConflict,
+			fmt.Sprintf("another device discovery job %s is currently running", s.running.Id), nil)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		Id:       uuid.NewString(),
+		Status:   JobRunning,
+		Options:  options,
+		Started:  time.Now().UnixNano() / 1e6,
+		Rejected: make(map[string]string),
+		cancel:   cancel,
+		batch:    make(chan struct{}, 1),
+	}
+	s.jobs[job.Id] = job
+	s.order = append(s.order, job.Id)
+	s.running = job
+	s.prune()
+	return job, ctx, nil
+}
+
+// finish must be called with the mutex locked
+func (s *jobStore) finish(job *Job, status JobStatus, err error) {
+	job.Status = status
+	job.Finished = time.Now().UnixNano() / 1e6
+	if err != nil {
+		job.Error = err.Error()
+	}
+	if s.running == job {
+		s.running = nil
+	}
+}
+
+// prune drops the oldest finished jobs, it must be called with the mutex locked
+func (s *jobStore) prune() {
+	for len(s.order) > maxFinishedJobs+1 {
+		id := s.order[0]
+		if s.jobs[id].Status == JobRunning {
+			return
+		}
+		delete(s.jobs, id)
+		s.order = s.order[1:]
+	}
+}
+
+func (s *jobStore) record(update func(job *Job)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running != nil {
+		update(s.running)
+	}
+}
+
+func (s *jobStore) setDrain(drain func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.drain = drain
+}
+
+func (s *jobStore) drainBatches() {
+	s.mutex.Lock()
+	drain := s.drain
+	s.mutex.Unlock()
+
+	if drain != nil {
+		drain()
+	}
+}
+
+// batchProcessed signals the running job a batch of devices was processed
+func (s *jobStore) batchProcessed() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running == nil {
+		return
+	}
+	select {
+	case s.running.batch <- struct{}{}:
+	default:
+	}
+}
+
+func (s *jobStore) snapshot(job *Job) Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return job.copy()
+}
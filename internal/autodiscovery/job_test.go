@@ -0,0 +1,189 @@
+package autodiscovery
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+type blockingDiscovery struct {
+	options chan map[string]string
+}
+
+func (b *blockingDiscovery) Discover() {}
+
+func (b *blockingDiscovery) DiscoverContext(ctx context.Context, options map[string]string) error {
+	b.options <- options
+	<-ctx.Done()
+	return nil
+}
+
+// sendingDiscovery sends the batches of devices and returns without waiting for them
+// to be processed
+type sendingDiscovery struct {
+	deviceCh chan<- []dsModels.DiscoveredDevice
+	batches  [][]dsModels.DiscoveredDevice
+}
+
+func (d *sendingDiscovery) Discover() {}
+
+func (d *sendingDiscovery) DiscoverContext(_ context.Context, _ map[string]string) error {
+	for _, batch := range d.batches {
+		d.deviceCh <- batch
+	}
+	return nil
+}
+
+// legacyDiscovery sends the devices in the background after Discover returned
+type legacyDiscovery struct {
+	deviceCh chan<- []dsModels.DiscoveredDevice
+	devices  []dsModels.DiscoveredDevice
+}
+
+func (d *legacyDiscovery) Discover() {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		d.deviceCh <- d.devices
+	}()
+}
+
+// silentDiscovery never sends any device
+type silentDiscovery struct{}
+
+func (silentDiscovery) Discover() {}
+
+// processDevices starts processing the batches sent on deviceCh as the device service
+// does, slowly, adding the devices except the ones named "rejected-*"
+func processDevices(t *testing.T, deviceCh <-chan []dsModels.DiscoveredDevice) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ProcessBatches(ctx, deviceCh, func(devices []dsModels.DiscoveredDevice) {
+			time.Sleep(20 * time.Millisecond)
+			for _, d := range devices {
+				RecordFound(d.Name)
+				if strings.HasPrefix(d.Name, "rejected-") {
+					RecordRejected(d.Name, "not accepted")
+				} else {
+					RecordAdded(d.Name)
+				}
+			}
+		})
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func waitFinished(t *testing.T, id string) Job {
+	require.Eventually(t, func() bool {
+		job, ok := JobById(id)
+		return ok && job.Status != JobRunning
+	}, time.Second, 10*time.Millisecond)
+	job, _ := JobById(id)
+	return job
+}
+
+func TestStartAndCancelJob(t *testing.T) {
+	lc := logger.NewMockClient()
+	discovery := &blockingDiscovery{options: make(chan map[string]string, 1)}
+	options := map[string]string{"subnet": "192.168.1.0/24"}
+
+	job, err := StartJob(discovery, options, lc)
+	require.NoError(t, err)
+	assert.Equal(t, JobRunning, job.Status)
+	assert.Equal(t, options, <-discovery.options)
+
+	_, err = StartJob(discovery, nil, lc)
+	require.Error(t, err)
+	assert.Equal(t, errors.KindStatusConflict, errors.Kind(err))
+
+	require.NoError(t, CancelJob(job.Id))
+	job = waitFinished(t, job.Id)
+	assert.Equal(t, JobCancelled, job.Status)
+	assert.NotZero(t, job.Finished)
+
+	err = CancelJob(job.Id)
+	assert.Equal(t, errors.KindStatusConflict, errors.Kind(err))
+	err = CancelJob("unknown")
+	assert.Equal(t, errors.KindEntityDoesNotExist, errors.Kind(err))
+}
+
+func TestJobResults(t *testing.T) {
+	deviceCh := make(chan []dsModels.DiscoveredDevice, 1)
+	processDevices(t, deviceCh)
+	discovery := &sendingDiscovery{deviceCh: deviceCh, batches: [][]dsModels.DiscoveredDevice{
+		{{Name: "found-1"}, {Name: "rejected-1"}},
+		{{Name: "found-2"}},
+		{{Name: "rejected-2"}},
+	}}
+
+	job, err := StartJob(discovery, nil, logger.NewMockClient())
+	require.NoError(t, err)
+
+	job = waitFinished(t, job.Id)
+	assert.Equal(t, JobCompleted, job.Status)
+	assert.Equal(t, []string{"found-1", "rejected-1", "found-2", "rejected-2"}, job.Found)
+	assert.Equal(t, []string{"found-1", "found-2"}, job.Added)
+	assert.Equal(t, map[string]string{"rejected-1": "not accepted", "rejected-2": "not accepted"}, job.Rejected)
+
+	RecordFound("late")
+	job, _ = JobById(job.Id)
+	assert.NotContains(t, job.Found, "late")
+	assert.Contains(t, AllJobs(), job)
+}
+
+func TestLegacyJobResults(t *testing.T) {
+	deviceCh := make(chan []dsModels.DiscoveredDevice, 1)
+	processDevices(t, deviceCh)
+	discovery := &legacyDiscovery{deviceCh: deviceCh, devices: []dsModels.DiscoveredDevice{
+		{Name: "found-1"}, {Name: "rejected-1"},
+	}}
+
+	job, err := StartJob(discovery, nil, logger.NewMockClient())
+	require.NoError(t, err)
+
+	job = waitFinished(t, job.Id)
+	assert.Equal(t, JobCompleted, job.Status)
+	assert.Equal(t, []string{"found-1", "rejected-1"}, job.Found)
+	assert.Equal(t, []string{"found-1"}, job.Added)
+	assert.Equal(t, map[string]string{"rejected-1": "not accepted"}, job.Rejected)
+}
+
+func TestLegacyJobTimeout(t *testing.T) {
+	timeout := legacyJobTimeout
+	legacyJobTimeout = 50 * time.Millisecond
+	defer func() { legacyJobTimeout = timeout }()
+
+	job, err := StartJob(silentDiscovery{}, nil, logger.NewMockClient())
+	require.NoError(t, err)
+	assert.Equal(t, JobCompleted, waitFinished(t, job.Id).Status)
+	assert.Empty(t, job.Found)
+}
+
+func TestCancelRunningJob(t *testing.T) {
+	_, ok := CancelRunningJob()
+	assert.False(t, ok)
+
+	discovery := &blockingDiscovery{options: make(chan map[string]string, 1)}
+	job, err := StartJob(discovery, nil, logger.NewMockClient())
+	require.NoError(t, err)
+	<-discovery.options
+
+	cancelled, ok := CancelRunningJob()
+	require.True(t, ok)
+	assert.Equal(t, job.Id, cancelled.Id)
+	assert.Equal(t, JobCancelled, waitFinished(t, job.Id).Status)
+	_, ok = CancelRunningJob()
+	assert.False(t, ok)
+}
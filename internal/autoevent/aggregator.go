@@ -0,0 +1,204 @@
+package autoevent
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+const (
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	AggregateMean  = "mean"
+	AggregateLast  = "last"
+	AggregateCount = "count"
+
+	// AggregationWindowTag is the event tag carrying the window of the aggregated readings
+	AggregationWindowTag = "aggregationWindow"
+)
+
+var defaultAggregateFunctions = []string{AggregateMin, AggregateMax, AggregateMean, AggregateLast, AggregateCount}
+
+// resourceStats holds the aggregation state of a single resource within a window.
+type resourceStats struct {
+	count    int64
+	numeric  int64
+	sum      float64
+	min, max float64
+	minRead  dtos.BaseReading
+	maxRead  dtos.BaseReading
+	lastRead dtos.BaseReading
+}
+
+// aggregator buffers the readings of an Executor and computes the aggregated
+// readings at the end of each window.
+type aggregator struct {
+	window    time.Duration
+	functions []string
+	template  *dtos.Event
+	order     []string
+	stats     map[string]*resourceStats
+}
+
+func newAggregator(aggregation models.AutoEventAggregation) (*aggregator, error) {
+	window, err := time.ParseDuration(aggregation.Window)
+	if err != nil {
+		return nil, err
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("aggregation window %s must be positive", aggregation.Window)
+	}
+
+	functions := aggregation.Functions
+	if len(functions) == 0 {
+		functions = defaultAggregateFunctions
+	}
+	for _, f := range functions {
+		switch f {
+		case AggregateMin, AggregateMax, AggregateMean, AggregateLast, AggregateCount:
+		default:
+			return nil, fmt.Errorf("unsupported aggregation function %s", f)
+		}
+	}
+
+	return &aggregator{
+		window:    window,
+		functions: functions,
+		stats:     make(map[string]*resourceStats),
+	}, nil
+}
+
+// add buffers the readings of the given events.
+func (a *aggregator) add(events []dtos.Event) {
+	for _, event := range events {
+		if len(event.Readings) == 0 {
+			continue
+		}
+		if a.template == nil {
+			e := event
+			a.template = &e
+		}
+		for _, r := range event.Readings {
+			a.addReading(r)
+		}
+	}
+}
+
+func (a *aggregator) addReading(r dtos.BaseReading) {
+	s, ok := a.stats[r.ResourceName]
+	if !ok {
+		s = &resourceStats{}
+		a.stats[r.ResourceName] = s
+		a.order = append(a.order, r.ResourceName)
+	}
+	s.count++
+	s.lastRead = r
+
+	v, ok := numericValue(r)
+	if !ok {
+		return
+	}
+	if s.numeric == 0 || v < s.min {
+		s.min, s.minRead = v, r
+	}
+	if s.numeric == 0 || v > s.max {
+		s.max, s.maxRead = v, r
+	}
+	s.numeric++
+	s.sum += v
+}
+
+// flush returns the event with the aggregated readings of the current window
+// and starts a new window. It returns false if nothing was read in the window.
+func (a *aggregator) flush() (dtos.Event, bool) {
+	if a.template == nil {
+		return dtos.Event{}, false
+	}
+
+	event := dtos.NewEvent(a.template.ProfileName, a.template.DeviceName)
+	event.Tags = map[string]string{AggregationWindowTag: a.window.String()}
+	for tag, value := range a.template.Tags {
+		event.Tags[tag] = value
+	}
+	for _, name := range a.order {
+		s := a.stats[name]
+		for _, f := range a.functions {
+			if r, ok := s.reading(f); ok {
+				r.ResourceName = name + "_" + f
+				event.Readings = append(event.Readings, r)
+			}
+		}
+	}
+
+	a.template = nil
+	a.order = nil
+	a.stats = make(map[string]*resourceStats)
+	return event, true
+}
+
+// reading returns the aggregated reading of the given function. Non-numeric
+// resources only provide last and count.
+func (s *resourceStats) reading(function string) (dtos.BaseReading, bool) {
+	last := s.lastRead
+	switch function {
+	case AggregateLast:
+		return last, true
+	case AggregateCount:
+		r, err := dtos.NewSimpleReading(last.ProfileName, last.DeviceName, last.ResourceName, contracts.ValueTypeInt64, s.count)
+		r.Tags = last.Tags
+		return r, err == nil
+	}
+
+	if s.numeric == 0 {
+		return dtos.BaseReading{}, false
+	}
+	switch function {
+	case AggregateMin:
+		return s.minRead, true
+	case AggregateMax:
+		return s.maxRead, true
+	case AggregateMean:
+		r, err := dtos.NewSimpleReading(last.ProfileName, last.DeviceName, last.ResourceName, contracts.ValueTypeFloat64, s.sum/float64(s.numeric))
+		r.Tags = last.Tags
+		return r, err == nil
+	}
+	return dtos.BaseReading{}, false
+}
+
+// numericValue parses the value of a numeric reading, floats may be either
+// plain decimal strings or base64 encoded big-endian bytes.
+func numericValue(r dtos.BaseReading) (float64, bool) {
+	switch r.ValueType {
+	case contracts.ValueTypeUint8, contracts.ValueTypeUint16, contracts.ValueTypeUint32, contracts.ValueTypeUint64:
+		v, err := strconv.ParseUint(r.Value, 10, 64)
+		return float64(v), err == nil
+	case contracts.ValueTypeInt8, contracts.ValueTypeInt16, contracts.ValueTypeInt32, contracts.ValueTypeInt64:
+		v, err := strconv.ParseInt(r.Value, 10, 64)
+		return float64(v), err == nil
+	case contracts.ValueTypeFloat32, contracts.ValueTypeFloat64:
+		if v, err := strconv.ParseFloat(r.Value, 64); err == nil {
+			return v, true
+		}
+		b, err := base64.StdEncoding.DecodeString(r.Value)
+		if err != nil {
+			return 0, false
+		}
+		reader := bytes.NewReader(b)
+		if len(b) == 4 {
+			var v float32
+			err = binary.Read(reader, binary.BigEndian, &v)
+			return float64(v), err == nil
+		}
+		var v float64
+		err = binary.Read(reader, binary.BigEndian, &v)
+		return v, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,81 @@
+package autoevent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+func testEvent(readings ...dtos.BaseReading) dtos.Event {
+	event := dtos.NewEvent("profile", "device")
+	event.Readings = readings
+	return event
+}
+
+func testReading(t *testing.T, resourceName string, valueType string, value interface{}) dtos.BaseReading {
+	r, err := dtos.NewSimpleReading("profile", "device", resourceName, valueType, value)
+	require.NoError(t, err)
+	return r
+}
+
+func TestAggregatorFlush(t *testing.T) {
+	agg, err := newAggregator(models.AutoEventAggregation{Window: "1m"})
+	require.NoError(t, err)
+
+	agg.add([]dtos.Event{testEvent(
+		testReading(t, "temperature", contracts.ValueTypeFloat32, float32(20)),
+		testReading(t, "status", contracts.ValueTypeString, "ok"))})
+	agg.add([]dtos.Event{testEvent(testReading(t, "temperature", contracts.ValueTypeFloat32, float32(30)))})
+	agg.add([]dtos.Event{testEvent(testReading(t, "temperature", contracts.ValueTypeFloat32, float32(25)))})
+
+	event, ok := agg.flush()
+	require.True(t, ok)
+	assert.Equal(t, "1m0s", event.Tags[AggregationWindowTag])
+
+	readings := make(map[string]dtos.BaseReading)
+	for _, r := range event.Readings {
+		readings[r.ResourceName] = r
+	}
+	assert.Len(t, readings, 7, "numeric resources get all functions, others only last and count")
+
+	min, _ := numericValue(readings["temperature_min"])
+	max, _ := numericValue(readings["temperature_max"])
+	mean, _ := numericValue(readings["temperature_mean"])
+	last, _ := numericValue(readings["temperature_last"])
+	assert.Equal(t, float64(20), min)
+	assert.Equal(t, float64(30), max)
+	assert.Equal(t, float64(25), mean)
+	assert.Equal(t, float64(25), last)
+	assert.Equal(t, "3", readings["temperature_count"].Value)
+	assert.Equal(t, "ok", readings["status_last"].Value)
+	assert.Equal(t, "1", readings["status_count"].Value)
+
+	_, ok = agg.flush()
+	assert.False(t, ok, "window should be empty after flush")
+}
+
+func TestAggregatorFunctions(t *testing.T) {
+	agg, err := newAggregator(models.AutoEventAggregation{Window: "10s", Functions: []string{AggregateMax}})
+	require.NoError(t, err)
+
+	agg.add([]dtos.Event{testEvent(testReading(t, "count", contracts.ValueTypeInt32, int32(-1)))})
+	agg.add([]dtos.Event{testEvent(testReading(t, "count", contracts.ValueTypeInt32, int32(7)))})
+
+	event, ok := agg.flush()
+	require.True(t, ok)
+	require.Len(t, event.Readings, 1)
+	assert.Equal(t, "count_max", event.Readings[0].ResourceName)
+	assert.Equal(t, "7", event.Readings[0].Value)
+}
+
+func TestNewAggregatorInvalid(t *testing.T) {
+	_, err := newAggregator(models.AutoEventAggregation{Window: "abc"})
+	assert.Error(t, err)
+	_, err = newAggregator(models.AutoEventAggregation{Window: "1m", Functions: []string{"median"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package autoevent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+// backoff tracks consecutive read failures of a device and computes the polling
+// interval to use until the device responds again. It is shared by all the
+// Executors of the device, since the OperatingState belongs to the device.
+type backoff struct {
+	threshold   int
+	maxInterval time.Duration
+	failures    int
+	mutex       sync.Mutex
+}
+
+func newBackoff(policy common.BackoffInfo) *backoff {
+	maxInterval, _ := time.ParseDuration(policy.MaxInterval)
+	return &backoff{
+		threshold:   policy.FailureThreshold,
+		maxInterval: maxInterval,
+	}
+}
+
+// enabled returns whether the back-off applies at all.
+func (b *backoff) enabled() bool {
+	return b.threshold > 0
+}
+
+// down returns whether the failure threshold has been reached.
+func (b *backoff) down() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.isDown()
+}
+
+func (b *backoff) isDown() bool {
+	return b.enabled() && b.failures >= b.threshold
+}
+
+// fail records a read failure and returns true if this failure is the one
+// that reached the threshold.
+func (b *backoff) fail() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures++
+	return b.enabled() && b.failures == b.threshold
+}
+
+// succeed resets the failure count and returns true if the device was
+// considered down before.
+func (b *backoff) succeed() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	wasDown := b.isDown()
+	b.failures = 0
+	return wasDown
+}
+
+// interval returns the polling interval derived from the base frequency of an
+// Executor, doubling for every failure past the threshold up to maxInterval.
+func (b *backoff) interval(base time.Duration) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.isDown() {
+		return base
+	}
+	maxInterval := b.maxInterval
+	if maxInterval < base {
+		maxInterval = base
+	}
+	d := base
+	for i := b.threshold; i < b.failures; i++ {
+		d *= 2
+		if d >= maxInterval {
+			return maxInterval
+		}
+	}
+	return d
+}
@@ -0,0 +1,74 @@
+package autoevent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+func TestBackoffInterval(t *testing.T) {
+	base := time.Second
+	bo := newBackoff(common.BackoffInfo{FailureThreshold: 3, MaxInterval: "10s"})
+
+	for i := 1; i < 3; i++ {
+		assert.False(t, bo.fail(), "threshold should not be reached after %d failures", i)
+		assert.Equal(t, base, bo.interval(base))
+	}
+	assert.True(t, bo.fail(), "threshold should be reached after 3 failures")
+	assert.Equal(t, base, bo.interval(base))
+
+	bo.fail()
+	assert.Equal(t, 2*time.Second, bo.interval(base))
+	bo.fail()
+	assert.Equal(t, 4*time.Second, bo.interval(base))
+	bo.fail()
+	assert.Equal(t, 8*time.Second, bo.interval(base))
+	bo.fail()
+	assert.Equal(t, 10*time.Second, bo.interval(base), "interval should be capped by MaxInterval")
+
+	assert.True(t, bo.succeed(), "device should recover from down state")
+	assert.Equal(t, base, bo.interval(base))
+	assert.False(t, bo.succeed())
+}
+
+func TestBackoffDisabled(t *testing.T) {
+	base := time.Second
+	bo := newBackoff(common.BackoffInfo{})
+	for i := 0; i < 10; i++ {
+		assert.False(t, bo.fail())
+	}
+	assert.Equal(t, base, bo.interval(base))
+	assert.False(t, bo.succeed())
+}
+
+func TestBackoffForDevice(t *testing.T) {
+	policy := common.BackoffInfo{
+		FailureThreshold: 3,
+		MaxInterval:      "5m",
+		Devices: map[string]common.BackoffInfo{
+			"meter-01": {FailureThreshold: 10},
+			"meter-02": {FailureThreshold: -1},
+		},
+	}
+
+	assert.Equal(t, common.BackoffInfo{FailureThreshold: 3, MaxInterval: "5m"}, policy.ForDevice("unknown"))
+	assert.Equal(t, common.BackoffInfo{FailureThreshold: 10, MaxInterval: "5m"}, policy.ForDevice("meter-01"))
+	assert.False(t, newBackoff(policy.ForDevice("meter-02")).enabled())
+}
+
+func TestBackoffSharedByExecutors(t *testing.T) {
+	// the Executors of a device at 1s and 10s count their failures together
+	bo := newBackoff(common.BackoffInfo{FailureThreshold: 2, MaxInterval: "5s"})
+	assert.False(t, bo.fail())
+	assert.True(t, bo.fail())
+	bo.fail()
+	assert.Equal(t, 2*time.Second, bo.interval(time.Second))
+	assert.Equal(t, 10*time.Second, bo.interval(10*time.Second), "interval should not be shorter than the frequency")
+
+	assert.True(t, bo.succeed())
+	assert.Equal(t, time.Second, bo.interval(time.Second))
+	assert.Equal(t, 10*time.Second, bo.interval(10*time.Second))
+}
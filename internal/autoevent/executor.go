@@ -9,6 +9,7 @@ package autoevent
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,9 +20,9 @@ import (
 
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
-	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/responses"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/command"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
@@ -30,10 +31,13 @@ import (
 
 type Executor struct {
 	deviceName   string
-	autoEvent    models.AutoEvent
+	autoEvents   []models.AutoEvent
 	lastReadings map[string]interface{}
 	duration     time.Duration
-	stop         bool
+	aggregator   *aggregator
+	backoff      *backoff
+	stopped      chan struct{}
+	stopOnce     sync.Once
 	rwMutex      *sync.RWMutex
 }
 
@@ -43,51 +47,218 @@ func (e *Executor) Run(ctx context.Context, wg *sync.WaitGroup, dic *di.Containe
 	defer wg.Done()
 
 	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	configuration := container.ConfigurationFrom(dic.Get)
+	bo := e.backoff
+	if bo == nil {
+		bo = newBackoff(configuration.Device.AutoEventBackoff.ForDevice(e.deviceName))
+	}
+
+	// the window channel stays nil and never fires unless the readings are aggregated
+	var window <-chan time.Time
+	if e.aggregator != nil {
+		ticker := time.NewTicker(e.aggregator.window)
+		defer ticker.Stop()
+		window = ticker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
+			e.flushAggregation(lc, dic)
 			return
-		case <-time.After(e.duration):
-			if e.stop {
-				return
-			}
+		case <-e.stopped:
+			e.flushAggregation(lc, dic)
+			return
+		case <-window:
+			e.flushAggregation(lc, dic)
+		case <-time.After(bo.interval(e.duration)):
+			// the manager stops the AutoEvents when the device service is locked and
+			// starts them again once unlocked, skip the reads in between
 			ds := container.DeviceServiceFrom(dic.Get)
 			if ds.AdminState == models.Locked {
-				lc.Info("AutoEvent - stopped for locked device service")
-				return
+				lc.Debug(fmt.Sprintf("AutoEvent - skipped for locked device service, device %s", e.deviceName))
+				continue
 			}
 
-			lc.Debug(fmt.Sprintf("AutoEvent - executing %v", e.autoEvent))
+			lc.Debug(fmt.Sprintf("AutoEvent - executing %v", e.autoEvents))
 			correlationID := uuid.NewString()
-			er, err := readResource(e, correlationID, dic)
+			events, err := readResources(e, correlationID, dic)
 			if err != nil {
-				lc.Error(fmt.Sprintf("AutoEvent - error occurs when reading device %s, resource %s, error: %v",
-					e.deviceName, e.autoEvent.Resource, err))
+				e.handleReadFailure(bo, err, lc, dic)
 				continue
 			}
+			if bo.succeed() {
+				lc.Info(fmt.Sprintf("AutoEvent - device %s is reachable again, resuming normal frequency %v", e.deviceName, e.duration))
+			}
+			// the device may be DOWN in the cache without this Executor having failed,
+			// e.g. after a restart of the AutoEvents
+			updateOperatingState(e.deviceName, models.Up, lc, dic)
 
-			if len(er.Event.Readings) > 0 {
-				// After the auto event executes a read command, it will create a goroutine to send out events.
-				// When the concurrent auto event amount becomes large, core-data might be hard to handle so many HTTP requests at the same time.
-				// The device service will get some network errors like EOF or Connection reset by peer.
-				// By adding a buffer here, the user can use the Service.AsyncBufferSize configuration to control the goroutine for sending events.
-				go func(correlationID string) {
-					m.autoeventBuffer <- true
-					common.SendEvent(er.Event, lc, container.CoredataEventClientFrom(dic.Get))
-					<-m.autoeventBuffer
-				}(correlationID)
-			} else {
-				lc.Debug(fmt.Sprintf("AutoEvent - no event generated when reading resource %s", e.autoEvent.Resource))
+			if e.aggregator != nil {
+				e.aggregator.add(events)
+				continue
+			}
+
+			resources := e.resources()
+			if configuration.Device.CombineAutoEvents {
+				events = combineEvents(events)
+				resources = []string{strings.Join(resources, ",")}
+			}
+			for i, event := range events {
+				if len(event.Readings) == 0 {
+					lc.Debug(fmt.Sprintf("AutoEvent - no event generated when reading resource %s", resources[i]))
+					continue
+				}
+				sendEvent(event, lc, dic)
 			}
 		}
 	}
 }
 
-func readResource(e *Executor, correlationID string, dic *di.Container) (res responses.EventResponse, err errors.EdgeX) {
-	vars := make(map[string]string, 2)
-	vars[common.NameVar] = e.deviceName
-	vars[common.CommandVar] = e.autoEvent.Resource
-	return command.CommandHandler(true, false, correlationID, vars, "", dic)
+// flushAggregation publishes the aggregated readings of the current window, if any.
+func (e *Executor) flushAggregation(lc logger.LoggingClient, dic *di.Container) {
+	if e.aggregator == nil {
+		return
+	}
+	event, ok := e.aggregator.flush()
+	if !ok {
+		lc.Debug(fmt.Sprintf("AutoEvent - no readings aggregated for device %s, resources %s", e.deviceName, e.resources()))
+		return
+	}
+	sendEvent(event, lc, dic)
+}
+
+func sendEvent(event dtos.Event, lc logger.LoggingClient, dic *di.Container) {
+	// After the auto event executes a read command, it will create a goroutine to send out events.
+	// When the concurrent auto event amount becomes large, core-data might be hard to handle so many HTTP requests at the same time.
+	// The device service will get some network errors like EOF or Connection reset by peer.
+	// By adding a buffer here, the user can use the Service.AsyncBufferSize configuration to control the goroutine for sending events.
+	go func() {
+		m.autoeventBuffer <- true
+		common.SendEvent(event, lc, container.CoredataEventClientFrom(dic.Get))
+		<-m.autoeventBuffer
+	}()
+}
+
+// handleReadFailure counts the failed read towards the back-off policy and marks
+// the device DOWN once the failure threshold is reached.
+func (e *Executor) handleReadFailure(bo *backoff, err errors.EdgeX, lc logger.LoggingClient, dic *di.Container) {
+	// locked or unknown devices are not a sign of an unreachable device
+	kind := errors.Kind(err)
+	if kind == errors.KindServiceLocked || kind == errors.KindEntityDoesNotExist {
+		lc.Error(fmt.Sprintf("AutoEvent - error occurs when reading device %s, resources %s, error: %v",
+			e.deviceName, e.resources(), err))
+		return
+	}
+
+	if bo.down() {
+		bo.fail()
+		lc.Debug(fmt.Sprintf("AutoEvent - device %s is still unreachable, next read in %v, error: %v",
+			e.deviceName, bo.interval(e.duration), err))
+		return
+	}
+
+	lc.Error(fmt.Sprintf("AutoEvent - error occurs when reading device %s, resources %s, error: %v",
+		e.deviceName, e.resources(), err))
+	if bo.fail() {
+		lc.Warn(fmt.Sprintf("AutoEvent - device %s failed %d consecutive reads, backing off up to %v",
+			e.deviceName, bo.threshold, bo.maxInterval))
+		updateOperatingState(e.deviceName, models.Down, lc, dic)
+	}
+}
+
+// updateOperatingState sets the OperatingState of the device in cache and metadata
+// if it differs from the given state.
+func updateOperatingState(deviceName string, state models.OperatingState, lc logger.LoggingClient, dic *di.Container) {
+	device, ok := cache.Devices().ForName(deviceName)
+	if !ok || device.OperatingState == state {
+		return
+	}
+
+	if err := cache.Devices().UpdateOperatingState(device.Id, state); err != nil {
+		lc.Error(fmt.Sprintf("AutoEvent - failed to update operating state of device %s in cache: %v", deviceName, err))
+		return
+	}
+	go common.UpdateOperatingState(device, state, lc, container.MetadataDeviceClientFrom(dic.Get))
+}
+
+// readResources reads the resources of all the AutoEvents of this Executor in a
+// single call and returns one event per AutoEvent. The resources having the tag of
+// an AutoEvent are looked up at each read, so that the resources added to the
+// profile with the tag are read without restarting the AutoEvents.
+func readResources(e *Executor, correlationID string, dic *di.Container) ([]dtos.Event, errors.EdgeX) {
+	device, ok := cache.Devices().ForName(e.deviceName)
+	if !ok {
+		return nil, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("device %s not found", e.deviceName), nil)
+	}
+
+	var cmds []string
+	counts := make([]int, len(e.autoEvents))
+	for i, ae := range e.autoEvents {
+		if ae.Tag == "" {
+			cmds = append(cmds, ae.Resource)
+			counts[i] = 1
+			continue
+		}
+		tagged := command.TaggedResources(device.ProfileName, ae.Tag)
+		cmds = append(cmds, tagged...)
+		counts[i] = len(tagged)
+	}
+
+	// an AutoEvent whose tag has no resources gets an event without readings
+	events := make([]dtos.Event, len(e.autoEvents))
+	if len(cmds) == 0 {
+		return events, nil
+	}
+	read, errs, err := command.ReadCommandsHandler(correlationID, e.deviceName, cmds, dic)
+	if err != nil {
+		return nil, err
+	}
+	// a read without any reading is a failed read, counted by the back-off
+	if err := allFailed(errs); err != nil {
+		return nil, err
+	}
+	// the readings of the other resources are still sent
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	for i, err := range errs {
+		if err != nil {
+			lc.Error(fmt.Sprintf("AutoEvent - error occurs when reading device %s, resource %s, error: %v", e.deviceName, cmds[i], err))
+		}
+	}
+	start := 0
+	for i, count := range counts {
+		if count > 0 {
+			events[i] = combineEvents(read[start : start+count])[0]
+		}
+		start += count
+	}
+	return events, nil
+}
+
+// allFailed returns the first error of errs if every command failed, nil otherwise
+func allFailed(errs []errors.EdgeX) errors.EdgeX {
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+// combineEvents merges the readings of the given events into the first one.
+func combineEvents(events []dtos.Event) []dtos.Event {
+	if len(events) <= 1 {
+		return events
+	}
+	combined := events[0]
+	combined.Readings = nil
+	for _, event := range events {
+		combined.Readings = append(combined.Readings, event.Readings...)
+	}
+	return []dtos.Event{combined}
 }
 
 func compareReadings(e *Executor, readings []dtos.BaseReading, lc logger.LoggingClient) bool {
@@ -117,16 +288,47 @@ func compareReadings(e *Executor, readings []dtos.BaseReading, lc logger.Logging
 			}
 			identical = false
 		default:
-			lc.Error("Error: unsupported reading type (%T) in autoevent - %v\n", e.lastReadings[r.ResourceName], e.autoEvent)
+			lc.Error("Error: unsupported reading type (%T) in autoevent - %v\n", e.lastReadings[r.ResourceName], e.autoEvents)
 			identical = false
 		}
 	}
 	return identical
 }
 
-// Stop marks this Executor stopped
+// Stop stops this Executor, its goroutine returns immediately
 func (e *Executor) Stop() {
-	e.stop = true
+	e.stopOnce.Do(func() {
+		close(e.stopped)
+	})
+}
+
+// resources returns the resources or commands read by this Executor, and the tags
+// as tag:<tag>.
+func (e *Executor) resources() []string {
+	resources := make([]string, len(e.autoEvents))
+	for i, ae := range e.autoEvents {
+		if ae.Tag != "" {
+			resources[i] = "tag:" + ae.Tag
+			continue
+		}
+		resources[i] = ae.Resource
+	}
+	return resources
+}
+
+// groupKey identifies the AutoEvents that can share this Executor: the same
+// frequency and the same aggregation settings.
+func (e *Executor) groupKey() string {
+	if e.aggregator == nil {
+		return e.duration.String()
+	}
+	return fmt.Sprintf("%s/%s/%s", e.duration, e.aggregator.window, strings.Join(e.aggregator.functions, ","))
+}
+
+// add groups another AutoEvent of the same device and frequency into this Executor,
+// so that they are read by a single driver call.
+func (e *Executor) add(ae models.AutoEvent) {
+	e.autoEvents = append(e.autoEvents, ae)
 }
 
 // NewExecutor creates an Executor for an AutoEvent
@@ -137,11 +339,19 @@ func NewExecutor(deviceName string, ae models.AutoEvent) (*Executor, error) {
 		return nil, err
 	}
 
+	var agg *aggregator
+	if ae.Aggregation.Window != "" {
+		if agg, err = newAggregator(ae.Aggregation); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Executor{
 		deviceName:   deviceName,
-		autoEvent:    ae,
+		autoEvents:   []models.AutoEvent{ae},
 		lastReadings: make(map[string]interface{}),
 		duration:     duration,
-		stop:         false,
+		aggregator:   agg,
+		stopped:      make(chan struct{}),
 		rwMutex:      &sync.RWMutex{}}, nil
 }
@@ -9,8 +9,11 @@ package autoevent
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
 )
@@ -109,3 +112,12 @@ func TestCompareReadings(t *testing.T) {
 		t.Error("compare readings with cache failed, the result should be true with unchanged readings")
 	}
 }
+
+func TestAllFailed(t *testing.T) {
+	first := errors.NewCommonEdgeX(errors.KindContractInvalid, "failed to transform deviceResource voltage", nil)
+	second := errors.NewCommonEdgeX(errors.KindServerError, "failed to transform deviceResource current", nil)
+
+	assert.Nil(t, allFailed(nil))
+	assert.Nil(t, allFailed([]errors.EdgeX{first, nil}))
+	assert.Equal(t, first, allFailed([]errors.EdgeX{first, second}))
+}
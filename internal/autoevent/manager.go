@@ -16,6 +16,7 @@ import (
 
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
 )
 
 type Manager interface {
@@ -23,10 +24,12 @@ type Manager interface {
 	StopAutoEvents()
 	RestartForDevice(deviceName string, dic *di.Container)
 	StopForDevice(deviceName string)
+	RunningAutoEvents() map[string][]models.AutoEvent
 }
 
 type manager struct {
 	executorMap     map[string][]*Executor
+	backoffs        map[string]*backoff // key is device name, shared by its Executors
 	ctx             context.Context
 	wg              *sync.WaitGroup
 	mutex           sync.Mutex
@@ -35,8 +38,7 @@ type manager struct {
 }
 
 var (
-	createOnce sync.Once
-	m          *manager
+	m *manager
 )
 
 // NewManager initiates the AutoEvent manager once
@@ -45,21 +47,30 @@ func NewManager(ctx context.Context, wg *sync.WaitGroup, bufferSize int, dic *di
 		ctx:             ctx,
 		wg:              wg,
 		executorMap:     make(map[string][]*Executor),
+		backoffs:        make(map[string]*backoff),
 		autoeventBuffer: make(chan bool, bufferSize),
 		dic:             dic}
 }
 
+// StartAutoEvents starts the AutoEvents of all the unlocked Devices in cache
+// which are not running yet. It does nothing while the Device Service is locked.
 func (m *manager) StartAutoEvents(dic *di.Container) bool {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	createOnce.Do(func() {
-		for _, d := range cache.Devices().All() {
-			if _, ok := m.executorMap[d.Name]; !ok {
-				executors := m.triggerExecutors(d.Name, d.AutoEvents, dic)
-				m.executorMap[d.Name] = executors
-			}
+
+	ds := container.DeviceServiceFrom(dic.Get)
+	if ds.AdminState == models.Locked {
+		lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+		lc.Info("AutoEvents are not started for locked device service")
+		return false
+	}
+
+	for _, d := range cache.Devices().All() {
+		if _, ok := m.executorMap[d.Name]; ok || d.AdminState == models.Locked {
+			continue
 		}
-	})
+		m.executorMap[d.Name] = m.triggerExecutors(d.Name, d.AutoEvents, dic)
+	}
 
 	return true
 }
@@ -69,11 +80,8 @@ func (m *manager) StopAutoEvents() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	for deviceName, executors := range m.executorMap {
-		for _, executor := range executors {
-			executor.Stop()
-		}
-		delete(m.executorMap, deviceName)
+	for deviceName := range m.executorMap {
+		m.stopForDevice(deviceName)
 	}
 }
 
@@ -81,20 +89,47 @@ func (m *manager) triggerExecutors(deviceName string, autoEvents []models.AutoEv
 	var executors []*Executor
 	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
 
+	// AutoEvents with the same frequency and aggregation share one Executor so
+	// that their resources are read by a single driver call
+	grouped := make(map[string]*Executor)
 	for _, autoEvent := range autoEvents {
 		executor, err := NewExecutor(deviceName, autoEvent)
 		if err != nil {
-			lc.Error(fmt.Sprintf("AutoEvent for resource %s cannot be created, %v", autoEvent.Resource, err))
+			lc.Error(fmt.Sprintf("AutoEvent for resource %s%s cannot be created, %v", autoEvent.Resource, autoEvent.Tag, err))
 			// skip this AutoEvent if it causes error during creation
 			continue
 		}
+		key := executor.groupKey()
+		if existing, ok := grouped[key]; ok {
+			existing.add(autoEvent)
+			continue
+		}
+		grouped[key] = executor
 		executors = append(executors, executor)
+	}
+
+	bo := m.backoffFor(deviceName, dic)
+	for _, executor := range executors {
+		executor.backoff = bo
 		go executor.Run(m.ctx, m.wg, dic)
 	}
 	return executors
 }
 
-// RestartForDevice restarts all the AutoEvents of the specific Device
+// backoffFor returns the back-off state of the device, kept while its AutoEvents
+// are restarted so that the failures are still counted towards the threshold.
+func (m *manager) backoffFor(deviceName string, dic *di.Container) *backoff {
+	if bo, ok := m.backoffs[deviceName]; ok {
+		return bo
+	}
+	configuration := container.ConfigurationFrom(dic.Get)
+	bo := newBackoff(configuration.Device.AutoEventBackoff.ForDevice(deviceName))
+	m.backoffs[deviceName] = bo
+	return bo
+}
+
+// RestartForDevice restarts all the AutoEvents of the specific Device. The AutoEvents
+// are only stopped if the Device or the Device Service is locked.
 func (m *manager) RestartForDevice(deviceName string, dic *di.Container) {
 	dc := dic
 	if dc == nil {
@@ -102,16 +137,25 @@ func (m *manager) RestartForDevice(deviceName string, dic *di.Container) {
 	}
 	lc := bootstrapContainer.LoggingClientFrom(dc.Get)
 
-	m.StopForDevice(deviceName)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.stopForDevice(deviceName)
 	d, ok := cache.Devices().ForName(deviceName)
 	if !ok {
 		lc.Error(fmt.Sprintf("there is no Device %s in cache to start AutoEvent", deviceName))
+		return
+	}
+	if d.AdminState == models.Locked {
+		lc.Info(fmt.Sprintf("AutoEvents of locked Device %s are paused", deviceName))
+		return
+	}
+	if ds := container.DeviceServiceFrom(dc.Get); ds.AdminState == models.Locked {
+		lc.Info(fmt.Sprintf("AutoEvents of Device %s are paused for locked device service", deviceName))
+		return
 	}
 
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	executors := m.triggerExecutors(deviceName, d.AutoEvents, dc)
-	m.executorMap[deviceName] = executors
+	m.executorMap[deviceName] = m.triggerExecutors(deviceName, d.AutoEvents, dc)
 }
 
 // StopForDevice stops all the AutoEvents of the specific Device
@@ -119,6 +163,11 @@ func (m *manager) StopForDevice(deviceName string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	m.stopForDevice(deviceName)
+	delete(m.backoffs, deviceName)
+}
+
+func (m *manager) stopForDevice(deviceName string) {
 	executors, ok := m.executorMap[deviceName]
 	if ok {
 		for _, executor := range executors {
@@ -128,6 +177,26 @@ func (m *manager) StopForDevice(deviceName string) {
 	}
 }
 
+// RunningAutoEvents returns the AutoEvents currently executed, by device name. The
+// devices without running AutoEvents are left out.
+func (m *manager) RunningAutoEvents() map[string][]models.AutoEvent {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	running := make(map[string][]models.AutoEvent, len(m.executorMap))
+	for deviceName, executors := range m.executorMap {
+		if len(executors) == 0 {
+			continue
+		}
+		var autoEvents []models.AutoEvent
+		for _, executor := range executors {
+			autoEvents = append(autoEvents, executor.autoEvents...)
+		}
+		running[deviceName] = autoEvents
+	}
+	return running
+}
+
 // GetManager returns Manager instance
 func GetManager() Manager {
 	return m
@@ -0,0 +1,41 @@
+package autoevent
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+)
+
+func TestRunningAutoEvents(t *testing.T) {
+	autoEvents := []models.AutoEvent{{Resource: "voltage", Frequency: "1s"}, {Resource: "current", Frequency: "1s"}}
+	manager := &manager{executorMap: map[string][]*Executor{
+		"meter":  {{autoEvents: autoEvents[:1]}, {autoEvents: autoEvents[1:]}},
+		"sensor": {},
+		"switch": nil,
+	}}
+
+	assert.Equal(t, map[string][]models.AutoEvent{"meter": autoEvents}, manager.RunningAutoEvents())
+}
+
+func TestBackoffPerDevice(t *testing.T) {
+	config := &common.ConfigurationStruct{}
+	config.Device.AutoEventBackoff = common.BackoffInfo{FailureThreshold: 3}
+	dic := di.NewContainer(di.ServiceConstructorMap{
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+	manager := &manager{executorMap: make(map[string][]*Executor), backoffs: make(map[string]*backoff)}
+
+	bo := manager.backoffFor("meter", dic)
+	assert.Same(t, bo, manager.backoffFor("meter", dic), "the Executors of a device should share its back-off")
+	assert.NotSame(t, bo, manager.backoffFor("sensor", dic))
+
+	manager.StopForDevice("meter")
+	assert.NotSame(t, bo, manager.backoffFor("meter", dic), "the back-off of a stopped device should be reset")
+}
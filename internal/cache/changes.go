@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// The change buses outlive the caches, which are replaced when they are initialized
+// or restored, so that subscriptions made before the initialization are kept.
+var (
+	deviceChanges           = &changeBus{subscribers: make(map[uint64]*subscriber)}
+	profileChanges          = &changeBus{subscribers: make(map[uint64]*subscriber)}
+	provisionWatcherChanges = &changeBus{subscribers: make(map[uint64]*subscriber)}
+)
+
+// SubscribeDeviceChanges calls handler for every change of the device cache until
+// the returned function is called. The changes are delivered to each subscriber in
+// the order they were made, on a goroutine of the subscriber, so a slow handler
+// neither blocks the cache nor the other subscribers.
+func SubscribeDeviceChanges(handler func(change dsModels.DeviceChange)) (unsubscribe func()) {
+	return deviceChanges.subscribe(func(change interface{}) {
+		handler(change.(dsModels.DeviceChange))
+	})
+}
+
+// SubscribeProfileChanges calls handler for every change of the profile cache until
+// the returned function is called, with the same ordering as SubscribeDeviceChanges.
+func SubscribeProfileChanges(handler func(change dsModels.ProfileChange)) (unsubscribe func()) {
+	return profileChanges.subscribe(func(change interface{}) {
+		handler(change.(dsModels.ProfileChange))
+	})
+}
+
+// SubscribeProvisionWatcherChanges calls handler for every change of the provision
+// watcher cache until the returned function is called, with the same ordering as
+// SubscribeDeviceChanges.
+func SubscribeProvisionWatcherChanges(handler func(change dsModels.ProvisionWatcherChange)) (unsubscribe func()) {
+	return provisionWatcherChanges.subscribe(func(change interface{}) {
+		handler(change.(dsModels.ProvisionWatcherChange))
+	})
+}
+
+// deviceChanged publishes a device change. Callers hold the cache mutex so that the
+// changes are published in the order they are made.
+func deviceChanged(changeType dsModels.ChangeType, before, after *models.Device) {
+	deviceChanges.publish(dsModels.DeviceChange{Type: changeType, Before: before, After: after})
+}
+
+func profileChanged(changeType dsModels.ChangeType, before, after *models.DeviceProfile) {
+	profileChanges.publish(dsModels.ProfileChange{Type: changeType, Before: before, After: after})
+}
+
+func provisionWatcherChanged(changeType dsModels.ChangeType, before, after *models.ProvisionWatcher) {
+	provisionWatcherChanges.publish(dsModels.ProvisionWatcherChange{Type: changeType, Before: before, After: after})
+}
+
+type changeBus struct {
+	subscribers map[uint64]*subscriber
+	next        uint64
+	mutex       sync.Mutex
+}
+
+func (b *changeBus) subscribe(handler func(change interface{})) func() {
+	s := &subscriber{handler: handler}
+	s.cond = sync.NewCond(&s.mutex)
+
+	b.mutex.Lock()
+	id := b.next
+	b.next++
+	b.subscribers[id] = s
+	b.mutex.Unlock()
+
+	go s.run()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subscribers, id)
+			b.mutex.Unlock()
+			s.close()
+		})
+	}
+}
+
+func (b *changeBus) publish(change interface{}) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, s := range b.subscribers {
+		s.enqueue(change)
+	}
+}
+
+// subscriber queues the changes without bound and delivers them one at a time
+type subscriber struct {
+	handler func(change interface{})
+	queue   []interface{}
+	closed  bool
+	mutex   sync.Mutex
+	cond    *sync.Cond
+}
+
+func (s *subscriber) enqueue(change interface{}) {
+	s.mutex.Lock()
+	s.queue = append(s.queue, change)
+	s.mutex.Unlock()
+	s.cond.Signal()
+}
+
+func (s *subscriber) close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.queue = nil
+	s.mutex.Unlock()
+	s.cond.Signal()
+}
+
+func (s *subscriber) run() {
+	for {
+		s.mutex.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mutex.Unlock()
+			return
+		}
+		change := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mutex.Unlock()
+
+		s.handler(change)
+	}
+}
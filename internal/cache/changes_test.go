@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+type deviceChangeRecorder struct {
+	changes []dsModels.DeviceChange
+	mutex   sync.Mutex
+}
+
+func (r *deviceChangeRecorder) record(change dsModels.DeviceChange) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+func (r *deviceChangeRecorder) recorded() []dsModels.DeviceChange {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]dsModels.DeviceChange(nil), r.changes...)
+}
+
+func TestSubscribeDeviceChanges(t *testing.T) {
+	newDeviceCache(nil)
+	recorder := &deviceChangeRecorder{}
+	unsubscribe := SubscribeDeviceChanges(recorder.record)
+
+	device := models.Device{Id: "d1", Name: "meter", AdminState: models.Unlocked}
+	require.NoError(t, Devices().Add(device))
+	updated := device
+	updated.Labels = []string{"floor-1"}
+	require.NoError(t, Devices().Update(updated))
+	require.NoError(t, Devices().UpdateAdminState("d1", models.Locked))
+	require.NoError(t, Devices().RemoveByName("meter"))
+	require.Error(t, Devices().RemoveByName("meter"))
+
+	require.Eventually(t, func() bool { return len(recorder.recorded()) == 4 }, time.Second, 10*time.Millisecond)
+	changes := recorder.recorded()
+	assert.Equal(t, dsModels.ChangeAdded, changes[0].Type)
+	assert.Nil(t, changes[0].Before)
+	assert.Equal(t, "meter", changes[0].After.Name)
+
+	assert.Equal(t, dsModels.ChangeUpdated, changes[1].Type)
+	assert.Empty(t, changes[1].Before.Labels)
+	assert.Equal(t, []string{"floor-1"}, changes[1].After.Labels)
+
+	assert.Equal(t, dsModels.ChangeUpdated, changes[2].Type)
+	assert.EqualValues(t, models.Unlocked, changes[2].Before.AdminState)
+	assert.EqualValues(t, models.Locked, changes[2].After.AdminState)
+
+	assert.Equal(t, dsModels.ChangeRemoved, changes[3].Type)
+	assert.EqualValues(t, models.Locked, changes[3].Before.AdminState)
+	assert.Nil(t, changes[3].After)
+
+	unsubscribe()
+	require.NoError(t, Devices().Add(device))
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, recorder.recorded(), 4)
+}
+
+func TestChangesAreDeliveredInOrder(t *testing.T) {
+	newProfileCache(nil)
+	var names []string
+	done := make(chan struct{})
+	unsubscribe := SubscribeProfileChanges(func(change dsModels.ProfileChange) {
+		// a slow subscriber must not reorder the changes
+		time.Sleep(time.Millisecond)
+		names = append(names, change.After.Name)
+		if len(names) == 10 {
+			close(done)
+		}
+	})
+	defer unsubscribe()
+
+	expected := make([]string, 10)
+	for i := range expected {
+		expected[i] = string(rune('a' + i))
+		require.NoError(t, Profiles().Add(models.DeviceProfile{Id: expected[i], Name: expected[i]}))
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("changes not delivered")
+	}
+	assert.Equal(t, expected, names)
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"sort"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+type indexKind int
+
+const (
+	labelIndex indexKind = iota
+	profileIndex
+	protocolPropertyIndex
+	operatingStateIndex
+)
+
+// indexKey is a label, a profile name, a protocol property key and value, whatever
+// the protocol, or an operating state
+type indexKey struct {
+	kind  indexKind
+	key   string
+	value string
+}
+
+// deviceIndex maps the index keys to the names of the devices having them
+type deviceIndex map[indexKey]map[string]struct{}
+
+func deviceIndexKeys(device models.Device) []indexKey {
+	keys := []indexKey{
+		{kind: profileIndex, key: device.ProfileName},
+		{kind: operatingStateIndex, key: string(device.OperatingState)},
+	}
+	for _, label := range device.Labels {
+		keys = append(keys, indexKey{kind: labelIndex, key: label})
+	}
+	for _, properties := range device.Protocols {
+		for key, value := range properties {
+			keys = append(keys, indexKey{kind: protocolPropertyIndex, key: key, value: value})
+		}
+	}
+	return keys
+}
+
+func (i deviceIndex) add(device models.Device) {
+	for _, key := range deviceIndexKeys(device) {
+		names, ok := i[key]
+		if !ok {
+			names = make(map[string]struct{})
+			i[key] = names
+		}
+		names[device.Name] = struct{}{}
+	}
+}
+
+func (i deviceIndex) remove(device models.Device) {
+	for _, key := range deviceIndexKeys(device) {
+		names := i[key]
+		delete(names, device.Name)
+		if len(names) == 0 {
+			delete(i, key)
+		}
+	}
+}
+
+// lookup returns the devices having the index key, sorted by name. The caller
+// holds the mutex of the device cache.
+func (d *deviceCache) lookup(key indexKey) []models.Device {
+	names := d.index[key]
+	devices := make([]models.Device, 0, len(names))
+	for name := range names {
+		devices = append(devices, *d.deviceMap[name])
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	return devices
+}
+
+// ForLabel returns the devices having the label, sorted by name.
+func (d *deviceCache) ForLabel(label string) []models.Device {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.lookup(indexKey{kind: labelIndex, key: label})
+}
+
+// ForProfile returns the devices using the profile, sorted by name.
+func (d *deviceCache) ForProfile(profileName string) []models.Device {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.lookup(indexKey{kind: profileIndex, key: profileName})
+}
+
+// ForProtocolProperty returns the devices having the protocol property key set to
+// the value in any of their protocols, sorted by name.
+func (d *deviceCache) ForProtocolProperty(key string, value string) []models.Device {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.lookup(indexKey{kind: protocolPropertyIndex, key: key, value: value})
+}
+
+// ForOperatingState returns the devices in the operating state, sorted by name.
+func (d *deviceCache) ForOperatingState(state models.OperatingState) []models.Device {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.lookup(indexKey{kind: operatingStateIndex, key: string(state)})
+}
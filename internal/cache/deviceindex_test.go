@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+func deviceNames(devices []models.Device) []string {
+	names := make([]string, len(devices))
+	for i, d := range devices {
+		names[i] = d.Name
+	}
+	return names
+}
+
+func TestDeviceIndexes(t *testing.T) {
+	dc := newDeviceCache([]models.Device{
+		{Id: "1", Name: "meter-b", ProfileName: "meter", Labels: []string{"floor-3"}, OperatingState: models.Up,
+			Protocols: map[string]models.ProtocolProperties{"modbus-rtu": {"Port": "COM2", "UnitID": "1"}}},
+		{Id: "2", Name: "meter-a", ProfileName: "meter", Labels: []string{"floor-3", "power"}, OperatingState: models.Up,
+			Protocols: map[string]models.ProtocolProperties{"modbus-rtu": {"Port": "COM2", "UnitID": "2"}}},
+		{Id: "3", Name: "sensor", ProfileName: "sensor", Labels: []string{"floor-1"}, OperatingState: models.Down,
+			Protocols: map[string]models.ProtocolProperties{"modbus-rtu": {"Port": "COM1", "UnitID": "1"}}},
+	})
+
+	assert.Equal(t, []string{"meter-a", "meter-b"}, deviceNames(dc.ForLabel("floor-3")))
+	assert.Equal(t, []string{"meter-a", "meter-b"}, deviceNames(dc.ForProfile("meter")))
+	assert.Equal(t, []string{"meter-a", "meter-b"}, deviceNames(dc.ForProtocolProperty("Port", "COM2")))
+	assert.Equal(t, []string{"meter-b", "sensor"}, deviceNames(dc.ForProtocolProperty("UnitID", "1")))
+	assert.Equal(t, []string{"sensor"}, deviceNames(dc.ForOperatingState(models.Down)))
+	assert.Empty(t, dc.ForLabel("unknown"))
+
+	sensor, _ := dc.ForName("sensor")
+	sensor.Labels = []string{"floor-3"}
+	sensor.Protocols = map[string]models.ProtocolProperties{"modbus-rtu": {"Port": "COM2", "UnitID": "3"}}
+	require.NoError(t, dc.Update(sensor))
+	assert.Equal(t, []string{"meter-a", "meter-b", "sensor"}, deviceNames(dc.ForLabel("floor-3")))
+	assert.Empty(t, dc.ForLabel("floor-1"))
+	assert.Empty(t, dc.ForProtocolProperty("Port", "COM1"))
+
+	require.NoError(t, dc.UpdateOperatingState("1", models.Down))
+	assert.Equal(t, []string{"meter-a"}, deviceNames(dc.ForOperatingState(models.Up)))
+	assert.Equal(t, []string{"meter-b", "sensor"}, deviceNames(dc.ForOperatingState(models.Down)))
+
+	require.NoError(t, dc.RemoveByName("meter-a"))
+	assert.Equal(t, []string{"meter-b"}, deviceNames(dc.ForProfile("meter")))
+	assert.Empty(t, dc.ForLabel("power"))
+	assert.Empty(t, dc.ForOperatingState(models.Up))
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
 
 var (
@@ -22,16 +23,22 @@ type DeviceCache interface {
 	ForName(name string) (models.Device, bool)
 	ForId(id string) (models.Device, bool)
 	All() []models.Device
+	ForLabel(label string) []models.Device
+	ForProfile(profileName string) []models.Device
+	ForProtocolProperty(key string, value string) []models.Device
+	ForOperatingState(state models.OperatingState) []models.Device
 	Add(device models.Device) errors.EdgeX
 	Update(device models.Device) errors.EdgeX
 	RemoveById(id string) errors.EdgeX
 	RemoveByName(name string) errors.EdgeX
 	UpdateAdminState(id string, state models.AdminState) errors.EdgeX
+	UpdateOperatingState(id string, state models.OperatingState) errors.EdgeX
 }
 
 type deviceCache struct {
 	deviceMap map[string]*models.Device // key is Device name
 	nameMap   map[string]string         // key is id, and value is Device name
+	index     deviceIndex
 	mutex     sync.Mutex
 }
 
@@ -39,11 +46,13 @@ func newDeviceCache(devices []models.Device) DeviceCache {
 	defaultSize := len(devices)
 	dMap := make(map[string]*models.Device, defaultSize)
 	nameMap := make(map[string]string, defaultSize)
+	index := make(deviceIndex)
 	for i, d := range devices {
 		dMap[d.Name] = &devices[i]
 		nameMap[d.Id] = d.Name
+		index.add(d)
 	}
-	dc = &deviceCache{deviceMap: dMap, nameMap: nameMap}
+	dc = &deviceCache{deviceMap: dMap, nameMap: nameMap, index: index}
 	return dc
 }
 
@@ -96,7 +105,11 @@ func (d *deviceCache) Add(device models.Device) errors.EdgeX {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	return d.add(device)
+	if err := d.add(device); err != nil {
+		return err
+	}
+	deviceChanged(dsModels.ChangeAdded, nil, &device)
+	return nil
 }
 
 func (d *deviceCache) add(device models.Device) errors.EdgeX {
@@ -107,6 +120,8 @@ func (d *deviceCache) add(device models.Device) errors.EdgeX {
 
 	d.deviceMap[device.Name] = &device
 	d.nameMap[device.Id] = device.Name
+	d.index.add(device)
+	snapshotChanged()
 	return nil
 }
 
@@ -115,11 +130,16 @@ func (d *deviceCache) Update(device models.Device) errors.EdgeX {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	before, _ := d.forId(device.Id)
 	if err := d.removeById(device.Id); err != nil {
 		return err
 	}
-
-	return d.add(device)
+	if err := d.add(device); err != nil {
+		deviceChanged(dsModels.ChangeRemoved, &before, nil)
+		return err
+	}
+	deviceChanged(dsModels.ChangeUpdated, &before, &device)
+	return nil
 }
 
 // RemoveById removes the specified device by id from the cache.
@@ -127,7 +147,21 @@ func (d *deviceCache) RemoveById(id string) errors.EdgeX {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	return d.removeById(id)
+	before, _ := d.forId(id)
+	if err := d.removeById(id); err != nil {
+		return err
+	}
+	deviceChanged(dsModels.ChangeRemoved, &before, nil)
+	return nil
+}
+
+// forId returns a copy of the device with the given id, the caller holds the mutex
+func (d *deviceCache) forId(id string) (models.Device, bool) {
+	device, ok := d.deviceMap[d.nameMap[id]]
+	if !ok {
+		return models.Device{}, false
+	}
+	return *device, true
 }
 
 func (d *deviceCache) removeById(id string) errors.EdgeX {
@@ -145,7 +179,16 @@ func (d *deviceCache) RemoveByName(name string) errors.EdgeX {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	return d.removeByName(name)
+	before, ok := d.deviceMap[name]
+	if !ok {
+		return d.removeByName(name)
+	}
+	removed := *before
+	if err := d.removeByName(name); err != nil {
+		return err
+	}
+	deviceChanged(dsModels.ChangeRemoved, &removed, nil)
+	return nil
 }
 
 func (d *deviceCache) removeByName(name string) errors.EdgeX {
@@ -157,6 +200,8 @@ func (d *deviceCache) removeByName(name string) errors.EdgeX {
 
 	delete(d.nameMap, device.Id)
 	delete(d.deviceMap, name)
+	d.index.remove(*device)
+	snapshotChanged()
 	return nil
 }
 
@@ -173,18 +218,40 @@ func (d *deviceCache) UpdateAdminState(id string, state models.AdminState) error
 		return errors.NewCommonEdgeX(errors.KindInvalidId, errMsg, nil)
 	}
 
+	before := *d.deviceMap[name]
 	d.deviceMap[name].AdminState = state
+	after := *d.deviceMap[name]
+	snapshotChanged()
+	deviceChanged(dsModels.ChangeUpdated, &before, &after)
 	return nil
 }
 
-func CheckProfileNotUsed(profileName string) bool {
-	for _, device := range dc.deviceMap {
-		if device.ProfileName == profileName {
-			return false
-		}
+// UpdateOperatingState updates the device operating state in cache by id.
+func (d *deviceCache) UpdateOperatingState(id string, state models.OperatingState) errors.EdgeX {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	name, ok := d.nameMap[id]
+	if !ok {
+		errMsg := fmt.Sprintf("failed to find device with given id %s in cache", id)
+		return errors.NewCommonEdgeX(errors.KindInvalidId, errMsg, nil)
 	}
 
-	return true
+	before := *d.deviceMap[name]
+	d.index.remove(before)
+	d.deviceMap[name].OperatingState = state
+	after := *d.deviceMap[name]
+	d.index.add(after)
+	snapshotChanged()
+	deviceChanged(dsModels.ChangeUpdated, &before, &after)
+	return nil
+}
+
+func CheckProfileNotUsed(profileName string) bool {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	return len(dc.index[indexKey{kind: profileIndex, key: profileName}]) == 0
 }
 
 func Devices() DeviceCache {
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/clients/interfaces"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+// ProfileSource returns the profile with the given name as defined, before it is
+// flattened. It is used to find the base profiles of a profile.
+type ProfileSource func(name string) (models.DeviceProfile, errors.EdgeX)
+
+// profileSource finds the base profiles of the profiles added to the cache, it is
+// set by InitCache
+var profileSource ProfileSource
+
+// MetadataProfiles returns a ProfileSource reading the profiles from Core Metadata
+func MetadataProfiles(dp interfaces.DeviceProfileClient) ProfileSource {
+	return func(name string) (models.DeviceProfile, errors.EdgeX) {
+		res, err := dp.DeviceProfileByName(context.Background(), name)
+		if err != nil {
+			return models.DeviceProfile{}, err
+		}
+		return dtos.ToDeviceProfileModel(res.Profile), nil
+	}
+}
+
+// FlattenProfile merges the device resources, device commands and core commands of
+// the base profiles listed in Extends into the profile. The bases are merged in
+// order, so a later base overrides the entries of an earlier one with the same
+// name, and the entries of the profile override those of all its bases. Bases may
+// extend other profiles and may contain only resources, as reusable blocks.
+// Extends of the flattened profile lists all the profiles it was resolved from,
+// the farthest ancestors first.
+func FlattenProfile(profile models.DeviceProfile, source ProfileSource) (models.DeviceProfile, errors.EdgeX) {
+	return flatten(profile, source, []string{profile.Name})
+}
+
+func flatten(profile models.DeviceProfile, source ProfileSource, path []string) (models.DeviceProfile, errors.EdgeX) {
+	if len(profile.Extends) == 0 {
+		return profile, nil
+	}
+
+	var bases models.DeviceProfile
+	var ancestors []string
+	for _, name := range profile.Extends {
+		for _, p := range path {
+			if p == name {
+				errMsg := fmt.Sprintf("profile %s extends itself through %v", name, append(path, name))
+				return models.DeviceProfile{}, errors.NewCommonEdgeX(errors.KindContractInvalid, errMsg, nil)
+			}
+		}
+		if source == nil {
+			errMsg := fmt.Sprintf("no source to find base profile %s of profile %s", name, profile.Name)
+			return models.DeviceProfile{}, errors.NewCommonEdgeX(errors.KindServerError, errMsg, nil)
+		}
+		base, err := source(name)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to find base profile %s of profile %s", name, profile.Name)
+			return models.DeviceProfile{}, errors.NewCommonEdgeX(errors.Kind(err), errMsg, err)
+		}
+		base, err = flatten(base, source, append(append([]string(nil), path...), name))
+		if err != nil {
+			return models.DeviceProfile{}, err
+		}
+
+		bases.DeviceResources = mergeDeviceResources(bases.DeviceResources, base.DeviceResources)
+		bases.DeviceCommands = mergeDeviceCommands(bases.DeviceCommands, base.DeviceCommands)
+		bases.CoreCommands = mergeCoreCommands(bases.CoreCommands, base.CoreCommands)
+		for _, ancestor := range append(base.Extends, name) {
+			ancestors = appendMissing(ancestors, ancestor)
+		}
+	}
+
+	profile.DeviceResources = mergeDeviceResources(bases.DeviceResources, profile.DeviceResources)
+	profile.DeviceCommands = mergeDeviceCommands(bases.DeviceCommands, profile.DeviceCommands)
+	profile.CoreCommands = mergeCoreCommands(bases.CoreCommands, profile.CoreCommands)
+	profile.Extends = ancestors
+	return profile, nil
+}
+
+// mergeDeviceResources returns the base resources with the overrides replacing
+// those with the same name in place, followed by the other overrides
+func mergeDeviceResources(base, overrides []models.DeviceResource) []models.DeviceResource {
+	merged := append([]models.DeviceResource(nil), base...)
+	index := make(map[string]int, len(merged))
+	for i, r := range merged {
+		index[r.Name] = i
+	}
+	for _, r := range overrides {
+		if i, ok := index[r.Name]; ok {
+			merged[i] = r
+			continue
+		}
+		index[r.Name] = len(merged)
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func mergeDeviceCommands(base, overrides []models.ProfileResource) []models.ProfileResource {
+	merged := append([]models.ProfileResource(nil), base...)
+	index := make(map[string]int, len(merged))
+	for i, c := range merged {
+		index[c.Name] = i
+	}
+	for _, c := range overrides {
+		if i, ok := index[c.Name]; ok {
+			merged[i] = c
+			continue
+		}
+		index[c.Name] = len(merged)
+		merged = append(merged, c)
+	}
+	return merged
+}
+
+func mergeCoreCommands(base, overrides []models.Command) []models.Command {
+	merged := append([]models.Command(nil), base...)
+	index := make(map[string]int, len(merged))
+	for i, c := range merged {
+		index[c.Name] = i
+	}
+	for _, c := range overrides {
+		if i, ok := index[c.Name]; ok {
+			merged[i] = c
+			continue
+		}
+		index[c.Name] = len(merged)
+		merged = append(merged, c)
+	}
+	return merged
+}
+
+func appendMissing(names []string, name string) []string {
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
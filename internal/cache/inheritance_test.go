@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+func profileSourceOf(profiles ...models.DeviceProfile) ProfileSource {
+	byName := make(map[string]models.DeviceProfile, len(profiles))
+	for _, p := range profiles {
+		byName[p.Name] = p
+	}
+	return func(name string) (models.DeviceProfile, errors.EdgeX) {
+		p, ok := byName[name]
+		if !ok {
+			return p, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, "not found", nil)
+		}
+		return p, nil
+	}
+}
+
+func float32Resource(name string) models.DeviceResource {
+	return models.DeviceResource{Name: name, Properties: models.PropertyValue{Type: "Float32", ReadWrite: "R"}}
+}
+
+func TestFlattenProfile(t *testing.T) {
+	base := models.DeviceProfile{
+		Name:            "meter-base",
+		DeviceResources: []models.DeviceResource{float32Resource("power"), float32Resource("voltage")},
+		DeviceCommands:  []models.ProfileResource{{Name: "all", Get: []models.ResourceOperation{{DeviceResource: "power"}}}},
+		CoreCommands:    []models.Command{{Name: "all", Get: true}},
+	}
+	block := models.DeviceProfile{
+		Name:            "energy-block",
+		DeviceResources: []models.DeviceResource{float32Resource("energy")},
+	}
+	product := models.DeviceProfile{
+		Name:    "meter-x",
+		Extends: []string{"meter-base", "energy-block"},
+		DeviceResources: []models.DeviceResource{
+			{Name: "voltage", Properties: models.PropertyValue{Type: "Float64", ReadWrite: "R"}},
+			float32Resource("frequency"),
+		},
+		DeviceCommands: []models.ProfileResource{{Name: "all", Get: []models.ResourceOperation{{DeviceResource: "power"}, {DeviceResource: "energy"}}}},
+	}
+	variant := models.DeviceProfile{Name: "meter-x2", Extends: []string{"meter-x"}}
+
+	flattened, err := FlattenProfile(variant, profileSourceOf(base, block, product))
+	require.NoError(t, err)
+	assert.Equal(t, "meter-x2", flattened.Name)
+	assert.Equal(t, []string{"meter-base", "energy-block", "meter-x"}, flattened.Extends)
+	assert.Equal(t, []models.DeviceResource{
+		float32Resource("power"),
+		{Name: "voltage", Properties: models.PropertyValue{Type: "Float64", ReadWrite: "R"}},
+		float32Resource("energy"),
+		float32Resource("frequency"),
+	}, flattened.DeviceResources)
+	assert.Equal(t, product.DeviceCommands, flattened.DeviceCommands)
+	assert.Equal(t, base.CoreCommands, flattened.CoreCommands)
+
+	_, err = FlattenProfile(variant, profileSourceOf(product))
+	assert.Equal(t, errors.KindEntityDoesNotExist, errors.Kind(err))
+
+	loop := models.DeviceProfile{Name: "meter-base", Extends: []string{"meter-x"}}
+	_, err = FlattenProfile(variant, profileSourceOf(loop, block, product))
+	assert.Equal(t, errors.KindContractInvalid, errors.Kind(err))
+}
+
+func TestProfileCacheFlattensProfiles(t *testing.T) {
+	base := models.DeviceProfile{Name: "meter-base", DeviceResources: []models.DeviceResource{float32Resource("power")}}
+	profileSource = profileSourceOf(base)
+	defer func() { profileSource = nil }()
+
+	pc := newProfileCache(nil)
+	require.NoError(t, pc.Add(models.DeviceProfile{
+		Id:             "1",
+		Name:           "meter-x",
+		Extends:        []string{"meter-base"},
+		DeviceCommands: []models.ProfileResource{{Name: "all", Get: []models.ResourceOperation{{DeviceResource: "power"}}}},
+	}))
+
+	_, ok := pc.DeviceResource("meter-x", "power")
+	assert.True(t, ok)
+	ros, err := pc.ResourceOperations("meter-x", "all", "get")
+	require.NoError(t, err)
+	assert.Len(t, ros, 1)
+	assert.Equal(t, []string{"meter-x"}, pc.Extending("meter-base"))
+	assert.Empty(t, pc.Extending("meter-x"))
+
+	err = pc.Add(models.DeviceProfile{Id: "2", Name: "meter-y", Extends: []string{"missing"}})
+	assert.Error(t, err)
+	_, ok = pc.ForName("meter-y")
+	assert.False(t, ok)
+}
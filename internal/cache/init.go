@@ -17,47 +17,47 @@ import (
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/profilelint"
 	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
 )
 
 var (
 	initOnce sync.Once
+	stale    bool
 )
 
-// Init basic state for cache
+// Init basic state for cache. If metadata is unreachable and snapshotFile is not
+// empty, the caches are restored from the snapshot file. InitCache returns true if
+// the caches were not loaded from metadata, they should then be reconciled once
+// metadata is available.
 func InitCache(serviceName string,
 	lc logger.LoggingClient,
 	dp interfaces.DeviceProfileClient,
 	dc interfaces.DeviceClient,
-	pwc interfaces.ProvisionWatcherClient) {
+	pwc interfaces.ProvisionWatcherClient,
+	snapshotFile string) bool {
 	initOnce.Do(func() {
+		profileSource = MetadataProfiles(dp)
 		ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
 		mdr, err := dc.DevicesByServiceName(ctx, serviceName, 0, -1)
 		if err != nil {
 			lc.Error("get device list error", err)
+			stale = true
+			if snapshotFile != "" {
+				if err := restoreSnapshot(snapshotFile); err != nil {
+					lc.Error(fmt.Sprintf("failed to restore the caches from snapshot %s: %v", snapshotFile, err))
+				} else {
+					lc.Warn(fmt.Sprintf("metadata unreachable, caches restored from snapshot %s", snapshotFile))
+					return
+				}
+			}
 		}
 		var dcs []models.Device
 		for i := range mdr.Devices {
 			dcs = append(dcs, dtos.ToDeviceModel(mdr.Devices[i]))
 		}
+		dps, dcs := loadProfiles(dcs, profileSource, lc)
 		newDeviceCache(dcs)
-
-		var (
-			dps   []models.DeviceProfile
-			dpMap = make(map[string]struct{})
-		)
-		for i := range dcs {
-			if _, ok := dpMap[dcs[i].ProfileName]; ok {
-				continue
-			}
-			dpr, err := dp.DeviceProfileByName(ctx, dcs[i].ProfileName)
-			if err != nil {
-				lc.Error(fmt.Sprintf("get device profile(%s) error: %+v", dcs[i].ProfileName, err))
-				continue
-			}
-			dpMap[dcs[i].ProfileName] = struct{}{}
-			dps = append(dps, dtos.ToDeviceProfileModel(dpr.Profile))
-		}
 		newProfileCache(dps)
 
 		pwr, err := pwc.ProvisionWatchersByServiceName(ctx, serviceName, 0, -1)
@@ -69,5 +69,56 @@ func InitCache(serviceName string,
 			pws = append(pws, dtos.ToProvisionWatcherModel(pwr.ProvisionWatchers[i]))
 		}
 		newProvisionWatcherCache(pws)
+		if !stale {
+			MetadataLoaded()
+		}
 	})
+	return stale
+}
+
+// loadProfiles returns the profiles of the devices and the devices to cache. The
+// invalid profiles are rejected as they are when added by a callback, and their
+// devices are not cached so that no command or AutoEvent runs against them.
+func loadProfiles(devices []models.Device, source ProfileSource, lc logger.LoggingClient) ([]models.DeviceProfile, []models.Device) {
+	var (
+		dps      []models.DeviceProfile
+		dpMap    = make(map[string]struct{})
+		rejected = make(map[string]struct{})
+	)
+	for i := range devices {
+		name := devices[i].ProfileName
+		if _, ok := dpMap[name]; ok {
+			continue
+		}
+		if _, ok := rejected[name]; ok {
+			continue
+		}
+		profile, err := source(name)
+		if err != nil {
+			lc.Error(fmt.Sprintf("get device profile(%s) error: %+v", name, err))
+			continue
+		}
+		profile, err = FlattenProfile(profile, source)
+		if err != nil {
+			lc.Error(fmt.Sprintf("resolve device profile(%s) error: %+v", name, err))
+			continue
+		}
+		if err := profilelint.Validate(profile); err != nil {
+			lc.Error(fmt.Sprintf("device profile(%s) rejected: %+v", name, err))
+			rejected[name] = struct{}{}
+			continue
+		}
+		dpMap[name] = struct{}{}
+		dps = append(dps, profile)
+	}
+
+	dcs := make([]models.Device, 0, len(devices))
+	for _, d := range devices {
+		if _, ok := rejected[d.ProfileName]; ok {
+			lc.Error(fmt.Sprintf("device %s skipped, its device profile(%s) is invalid", d.Name, d.ProfileName))
+			continue
+		}
+		dcs = append(dcs, d)
+	}
+	return dps, dcs
 }
@@ -26,7 +26,7 @@ func TestInitCache(t *testing.T) {
 	dp := &mock.DeviceProfileClientMock{}
 	dc := &mock.DeviceClientMock{}
 	pwc := &mock.ProvisionWatcherClientMock{}
-	InitCache(serviceName, lc, dp, dc, pwc)
+	InitCache(serviceName, lc, dp, dc, pwc, "")
 
 	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
 
@@ -63,3 +63,22 @@ func TestInitCache(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadProfilesRejectsInvalidProfiles(t *testing.T) {
+	valid := models.DeviceProfile{Name: "valid", DeviceResources: []models.DeviceResource{float32Resource("power")}}
+	invalid := models.DeviceProfile{
+		Name:            "invalid",
+		DeviceResources: []models.DeviceResource{float32Resource("power")},
+		DeviceCommands:  []models.ProfileResource{{Name: "all", Get: []models.ResourceOperation{{DeviceResource: "missing"}}}},
+	}
+	devices := []models.Device{
+		{Name: "d1", ProfileName: "valid"},
+		{Name: "d2", ProfileName: "invalid"},
+		{Name: "d3", ProfileName: "valid"},
+		{Name: "d4", ProfileName: "invalid"},
+	}
+
+	dps, dcs := loadProfiles(devices, profileSourceOf(valid, invalid), logger.NewMockClient())
+	assert.Equal(t, []models.DeviceProfile{valid}, dps)
+	assert.Equal(t, []models.Device{devices[0], devices[2]}, dcs)
+}
@@ -8,12 +8,15 @@ package cache
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/profilelint"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
 
 var (
@@ -28,7 +31,9 @@ type ProfileCache interface {
 	Update(profile models.DeviceProfile) errors.EdgeX
 	RemoveById(id string) errors.EdgeX
 	RemoveByName(name string) errors.EdgeX
+	Extending(name string) []string
 	DeviceResource(profileName string, resourceName string) (models.DeviceResource, bool)
+	ResourcesByTag(profileName string, tag string) []models.DeviceResource
 	CommandExists(profileName string, cmd string, method string) (bool, errors.EdgeX)
 	ResourceOperations(profileName string, cmd string, method string) ([]models.ResourceOperation, errors.EdgeX)
 	ResourceOperation(profileName string, deviceResource string, method string) (models.ResourceOperation, errors.EdgeX)
@@ -41,6 +46,7 @@ type profileCache struct {
 	getResourceOperationsMap map[string]map[string][]models.ResourceOperation
 	setResourceOperationsMap map[string]map[string][]models.ResourceOperation
 	commandsMap              map[string]map[string]models.Command
+	descriptors              *valueDescriptorCache
 	mutex                    sync.Mutex
 }
 
@@ -65,7 +71,8 @@ func newProfileCache(profiles []models.DeviceProfile) ProfileCache {
 		deviceResourceMap:        drMap,
 		getResourceOperationsMap: getRoMap,
 		setResourceOperationsMap: setRoMap,
-		commandsMap:              cmdMap}
+		commandsMap:              cmdMap,
+		descriptors:              newValueDescriptorCache(profiles)}
 	return pc
 }
 
@@ -111,11 +118,22 @@ func (p *profileCache) All() []models.DeviceProfile {
 
 // Add adds a new profile to the cache. This method is used to populate the
 // profile cache with pre-existing or recently-added profiles from Core Metadata.
+// The profile is flattened with its base profiles, and a profile with problems
+// is rejected with all its problems.
 func (p *profileCache) Add(profile models.DeviceProfile) errors.EdgeX {
+	profile, err := resolveProfile(profile)
+	if err != nil {
+		return err
+	}
+
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.add(profile)
+	if err := p.add(profile); err != nil {
+		return err
+	}
+	profileChanged(dsModels.ChangeAdded, nil, &profile)
+	return nil
 }
 
 func (p *profileCache) add(profile models.DeviceProfile) errors.EdgeX {
@@ -129,6 +147,8 @@ func (p *profileCache) add(profile models.DeviceProfile) errors.EdgeX {
 	p.deviceResourceMap[profile.Name] = deviceResourceSliceToMap(profile.DeviceResources)
 	p.getResourceOperationsMap[profile.Name], p.setResourceOperationsMap[profile.Name] = profileResourceSliceToMaps(profile.DeviceCommands)
 	p.commandsMap[profile.Name] = commandSliceToMap(profile.CoreCommands)
+	p.descriptors.generate(profile)
+	snapshotChanged()
 	return nil
 }
 
@@ -165,16 +185,27 @@ func commandSliceToMap(commands []models.Command) map[string]models.Command {
 	return result
 }
 
-// Update updates the profile in the cache
+// Update updates the profile in the cache. The profile is flattened with its base
+// profiles, and a profile with problems is rejected and the cached profile is kept.
 func (p *profileCache) Update(profile models.DeviceProfile) errors.EdgeX {
+	profile, err := resolveProfile(profile)
+	if err != nil {
+		return err
+	}
+
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	before, _ := p.forId(profile.Id)
 	if err := p.removeById(profile.Id); err != nil {
 		return err
 	}
-
-	return p.add(profile)
+	if err := p.add(profile); err != nil {
+		profileChanged(dsModels.ChangeRemoved, &before, nil)
+		return err
+	}
+	profileChanged(dsModels.ChangeUpdated, &before, &profile)
+	return nil
 }
 
 // RemoveById removes the specified profile by id from the cache.
@@ -182,7 +213,21 @@ func (p *profileCache) RemoveById(id string) errors.EdgeX {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.removeById(id)
+	before, _ := p.forId(id)
+	if err := p.removeById(id); err != nil {
+		return err
+	}
+	profileChanged(dsModels.ChangeRemoved, &before, nil)
+	return nil
+}
+
+// forId returns a copy of the profile with the given id, the caller holds the mutex
+func (p *profileCache) forId(id string) (models.DeviceProfile, bool) {
+	profile, ok := p.deviceProfileMap[p.nameMap[id]]
+	if !ok {
+		return models.DeviceProfile{}, false
+	}
+	return *profile, true
 }
 
 func (p *profileCache) removeById(id string) errors.EdgeX {
@@ -200,7 +245,16 @@ func (p *profileCache) RemoveByName(name string) errors.EdgeX {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.removeByName(name)
+	before, ok := p.deviceProfileMap[name]
+	if !ok {
+		return p.removeByName(name)
+	}
+	removed := *before
+	if err := p.removeByName(name); err != nil {
+		return err
+	}
+	profileChanged(dsModels.ChangeRemoved, &removed, nil)
+	return nil
 }
 
 func (p *profileCache) removeByName(name string) errors.EdgeX {
@@ -216,9 +270,39 @@ func (p *profileCache) removeByName(name string) errors.EdgeX {
 	delete(p.getResourceOperationsMap, name)
 	delete(p.setResourceOperationsMap, name)
 	delete(p.commandsMap, name)
+	p.descriptors.remove(name)
+	snapshotChanged()
 	return nil
 }
 
+// resolveProfile flattens the profile with its base profiles and validates the result
+func resolveProfile(profile models.DeviceProfile) (models.DeviceProfile, errors.EdgeX) {
+	profile, err := FlattenProfile(profile, profileSource)
+	if err != nil {
+		return profile, err
+	}
+	return profile, profilelint.Validate(profile)
+}
+
+// Extending returns the names of the cached profiles having the given profile among
+// their base profiles, directly or through other base profiles.
+func (p *profileCache) Extending(name string) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var names []string
+	for _, profile := range p.deviceProfileMap {
+		for _, base := range profile.Extends {
+			if base == name {
+				names = append(names, profile.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // DeviceResource returns the DeviceResource with given profileName and resourceName
 func (p *profileCache) DeviceResource(profileName string, resourceName string) (models.DeviceResource, bool) {
 	p.mutex.Lock()
@@ -233,6 +317,25 @@ func (p *profileCache) DeviceResource(profileName string, resourceName string) (
 	return dr, ok
 }
 
+// ResourcesByTag returns the DeviceResources of the profile with the given tag, in
+// the order of the profile
+func (p *profileCache) ResourcesByTag(profileName string, tag string) []models.DeviceResource {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	profile, ok := p.deviceProfileMap[profileName]
+	if !ok {
+		return nil
+	}
+	var resources []models.DeviceResource
+	for _, dr := range profile.DeviceResources {
+		if dr.Tag == tag {
+			resources = append(resources, dr)
+		}
+	}
+	return resources
+}
+
 // CommandExists returns a bool indicating whether the specified command exists for the
 // specified (by name) device. If the specified device doesn't exist, an error is returned.
 func (p *profileCache) CommandExists(profileName string, cmd string, method string) (bool, errors.EdgeX) {
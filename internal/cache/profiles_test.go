@@ -217,3 +217,23 @@ func TestProfileCache_ResourceOperation(t *testing.T) {
 		t.Error("the input deviceResource name of resource operation is not belong to DeviceProfileRandomBoolGenerator, supposed to get an error")
 	}
 }
+
+func TestProfileCache_ResourcesByTag(t *testing.T) {
+	dpc := newProfileCache([]models.DeviceProfile{{
+		Id:   "1",
+		Name: "meter",
+		DeviceResources: []models.DeviceResource{
+			{Name: "voltage", Tag: "measurements"},
+			{Name: "serial"},
+			{Name: "power", Tag: "measurements"},
+		},
+	}})
+
+	resources := dpc.ResourcesByTag("meter", "measurements")
+	if assert.Len(t, resources, 2) {
+		assert.Equal(t, "voltage", resources[0].Name)
+		assert.Equal(t, "power", resources[1].Name)
+	}
+	assert.Empty(t, dpc.ResourcesByTag("meter", "unknown"))
+	assert.Empty(t, dpc.ResourcesByTag("unknown", "measurements"))
+}
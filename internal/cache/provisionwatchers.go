@@ -11,6 +11,7 @@ import (
 
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
 
 var (
@@ -82,7 +83,11 @@ func (p *provisionWatcherCache) Add(watcher models.ProvisionWatcher) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.add(watcher)
+	if err := p.add(watcher); err != nil {
+		return err
+	}
+	provisionWatcherChanged(dsModels.ChangeAdded, nil, &watcher)
+	return nil
 }
 
 func (p *provisionWatcherCache) add(watcher models.ProvisionWatcher) error {
@@ -93,6 +98,7 @@ func (p *provisionWatcherCache) add(watcher models.ProvisionWatcher) error {
 
 	p.pwMap[watcher.Name] = &watcher
 	p.nameMap[watcher.Id] = watcher.Name
+	snapshotChanged()
 	return nil
 }
 
@@ -101,10 +107,16 @@ func (p *provisionWatcherCache) Update(watcher models.ProvisionWatcher) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	before, _ := p.forId(watcher.Id)
 	if err := p.removeById(watcher.Id); err != nil {
 		return err
 	}
-	return p.add(watcher)
+	if err := p.add(watcher); err != nil {
+		provisionWatcherChanged(dsModels.ChangeRemoved, &before, nil)
+		return err
+	}
+	provisionWatcherChanged(dsModels.ChangeUpdated, &before, &watcher)
+	return nil
 }
 
 // RemoveById removes the specified provision watcher by id from the cache.
@@ -112,7 +124,21 @@ func (p *provisionWatcherCache) RemoveById(id string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.removeById(id)
+	before, _ := p.forId(id)
+	if err := p.removeById(id); err != nil {
+		return err
+	}
+	provisionWatcherChanged(dsModels.ChangeRemoved, &before, nil)
+	return nil
+}
+
+// forId returns a copy of the provision watcher with the given id, the caller holds the mutex
+func (p *provisionWatcherCache) forId(id string) (models.ProvisionWatcher, bool) {
+	watcher, ok := p.pwMap[p.nameMap[id]]
+	if !ok {
+		return models.ProvisionWatcher{}, false
+	}
+	return *watcher, true
 }
 
 func (p *provisionWatcherCache) removeById(id string) error {
@@ -130,7 +156,16 @@ func (p *provisionWatcherCache) RemoveByName(name string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.removeByName(name)
+	before, ok := p.pwMap[name]
+	if !ok {
+		return p.removeByName(name)
+	}
+	removed := *before
+	if err := p.removeByName(name); err != nil {
+		return err
+	}
+	provisionWatcherChanged(dsModels.ChangeRemoved, &removed, nil)
+	return nil
 }
 
 func (p *provisionWatcherCache) removeByName(name string) error {
@@ -142,6 +177,7 @@ func (p *provisionWatcherCache) removeByName(name string) error {
 
 	delete(p.pwMap, name)
 	delete(p.nameMap, watcher.Id)
+	snapshotChanged()
 	return nil
 }
 
@@ -156,7 +192,11 @@ func (p *provisionWatcherCache) UpdateAdminState(id string, state models.AdminSt
 		return errors.NewCommonEdgeX(errors.KindInvalidId, errMsg, nil)
 	}
 
+	before := *p.pwMap[name]
 	p.pwMap[name].AdminState = state
+	after := *p.pwMap[name]
+	snapshotChanged()
+	provisionWatcherChanged(dsModels.ChangeUpdated, &before, &after)
 	return nil
 }
 
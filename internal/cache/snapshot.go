@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+)
+
+// Snapshot is the content of the cache snapshot file, used to start with the last
+// known devices, profiles and provision watchers when metadata is unreachable
+type Snapshot struct {
+	Devices           []dtos.Device           `json:"devices"`
+	Profiles          []dtos.DeviceProfile    `json:"profiles"`
+	ProvisionWatchers []dtos.ProvisionWatcher `json:"provisionWatchers"`
+}
+
+var (
+	snapshotMutex   sync.Mutex
+	snapshotChanges chan struct{}
+	// snapshotLoaded is set once the caches hold the devices, profiles and provision
+	// watchers of metadata or of the snapshot, so that empty caches never overwrite
+	// the snapshot when both metadata and the snapshot were unavailable at startup
+	snapshotLoaded bool
+)
+
+// RunSnapshotWriter writes the caches to the snapshot file after every change of
+// the caches until ctx is done. Changes made while the file is written are
+// coalesced into the next write. Nothing is written until the caches were loaded
+// from metadata or restored from the snapshot.
+func RunSnapshotWriter(ctx context.Context, wg *sync.WaitGroup, path string, lc logger.LoggingClient) {
+	changes := make(chan struct{}, 1)
+	snapshotMutex.Lock()
+	snapshotChanges = changes
+	snapshotMutex.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				snapshotMutex.Lock()
+				snapshotChanges = nil
+				snapshotMutex.Unlock()
+				return
+			case <-changes:
+				if err := WriteSnapshot(path); err != nil {
+					lc.Error(fmt.Sprintf("failed to write cache snapshot %s: %v", path, err))
+				}
+			}
+		}
+	}()
+
+	// write the current state
+	snapshotChanged()
+}
+
+// WriteSnapshot writes the caches to the snapshot file. The file is replaced
+// atomically so that a crash never leaves a truncated snapshot.
+func WriteSnapshot(path string) error {
+	var snapshot Snapshot
+	for _, d := range Devices().All() {
+		snapshot.Devices = append(snapshot.Devices, dtos.FromDeviceModelToDTO(d))
+	}
+	for _, p := range Profiles().All() {
+		snapshot.Profiles = append(snapshot.Profiles, dtos.FromDeviceProfileModelToDTO(p))
+	}
+	for _, pw := range ProvisionWatchers().All() {
+		snapshot.ProvisionWatchers = append(snapshot.ProvisionWatchers, dtos.FromProvisionWatcherModelToDTO(pw))
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot reads the snapshot file
+func LoadSnapshot(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	err = json.Unmarshal(data, &snapshot)
+	return snapshot, err
+}
+
+// restoreSnapshot fills the caches with the snapshot file
+func restoreSnapshot(path string) error {
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		return err
+	}
+
+	devices := make([]models.Device, len(snapshot.Devices))
+	for i, d := range snapshot.Devices {
+		devices[i] = dtos.ToDeviceModel(d)
+	}
+	profiles := make([]models.DeviceProfile, len(snapshot.Profiles))
+	for i, p := range snapshot.Profiles {
+		profiles[i] = dtos.ToDeviceProfileModel(p)
+	}
+	pws := make([]models.ProvisionWatcher, len(snapshot.ProvisionWatchers))
+	for i, pw := range snapshot.ProvisionWatchers {
+		pws[i] = dtos.ToProvisionWatcherModel(pw)
+	}
+	newDeviceCache(devices)
+	newProfileCache(profiles)
+	newProvisionWatcherCache(pws)
+
+	snapshotMutex.Lock()
+	snapshotLoaded = true
+	snapshotMutex.Unlock()
+	return nil
+}
+
+// MetadataLoaded records that the caches were loaded from metadata, by a successful
+// reconciliation when they could not be at startup, and writes the snapshot.
+func MetadataLoaded() {
+	snapshotMutex.Lock()
+	snapshotLoaded = true
+	snapshotMutex.Unlock()
+	snapshotChanged()
+}
+
+// snapshotChanged schedules a write of the snapshot file, if enabled
+func snapshotChanged() {
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	if snapshotChanges == nil || !snapshotLoaded {
+		return
+	}
+	select {
+	case snapshotChanges <- struct{}{}:
+	default:
+		// a write is already pending
+	}
+}
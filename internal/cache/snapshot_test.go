@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	device := models.Device{Id: "d1", Name: "meter", ProfileName: "profile", AdminState: models.Unlocked,
+		Protocols: map[string]models.ProtocolProperties{"tcp": {"Address": "10.0.0.1"}}}
+	profile := models.DeviceProfile{Id: "p1", Name: "profile"}
+	watcher := models.ProvisionWatcher{Id: "w1", Name: "watcher", Identifiers: map[string]string{"Address": ".*"}}
+
+	newDeviceCache([]models.Device{device})
+	newProfileCache([]models.DeviceProfile{profile})
+	newProvisionWatcherCache([]models.ProvisionWatcher{watcher})
+	require.NoError(t, WriteSnapshot(path))
+
+	newDeviceCache(nil)
+	newProfileCache(nil)
+	newProvisionWatcherCache(nil)
+	require.NoError(t, restoreSnapshot(path))
+
+	restoredDevice, ok := Devices().ForName("meter")
+	require.True(t, ok)
+	assert.Equal(t, device.Protocols, restoredDevice.Protocols)
+	_, ok = Profiles().ForName("profile")
+	assert.True(t, ok)
+	restoredWatcher, ok := ProvisionWatchers().ForName("watcher")
+	require.True(t, ok)
+	assert.Equal(t, watcher.Identifiers, restoredWatcher.Identifiers)
+
+	assert.Error(t, restoreSnapshot(filepath.Join(t.TempDir(), "missing.json")))
+}
+
+func TestSnapshotWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	newDeviceCache(nil)
+	newProfileCache(nil)
+	newProvisionWatcherCache(nil)
+
+	MetadataLoaded()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	RunSnapshotWriter(ctx, wg, path, logger.NewMockClient())
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	require.NoError(t, Devices().Add(models.Device{Id: "d1", Name: "meter"}))
+	assert.Eventually(t, func() bool {
+		snapshot, err := LoadSnapshot(path)
+		return err == nil && len(snapshot.Devices) == 1 && snapshot.Devices[0].Name == "meter"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSnapshotWriterWaitsForLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	newDeviceCache([]models.Device{{Id: "d1", Name: "meter"}})
+	newProfileCache(nil)
+	newProvisionWatcherCache(nil)
+	require.NoError(t, WriteSnapshot(path))
+
+	// neither metadata nor the snapshot could be loaded
+	snapshotMutex.Lock()
+	snapshotLoaded = false
+	snapshotMutex.Unlock()
+	newDeviceCache(nil)
+	assert.Error(t, restoreSnapshot(filepath.Join(t.TempDir(), "missing.json")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	RunSnapshotWriter(ctx, wg, path, logger.NewMockClient())
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	require.NoError(t, Devices().Add(models.Device{Id: "d2", Name: "sensor"}))
+	time.Sleep(50 * time.Millisecond)
+	snapshot, err := LoadSnapshot(path)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Devices, 1)
+	assert.Equal(t, "meter", snapshot.Devices[0].Name, "the snapshot should not be overwritten before a load")
+
+	MetadataLoaded()
+	assert.Eventually(t, func() bool {
+		snapshot, err := LoadSnapshot(path)
+		return err == nil && len(snapshot.Devices) == 1 && snapshot.Devices[0].Name == "sensor"
+	}, time.Second, 10*time.Millisecond)
+}
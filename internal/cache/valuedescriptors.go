@@ -8,121 +8,112 @@
 package cache
 
 import (
-	"fmt"
 	"sync"
 
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
 
 var (
 	vdc *valueDescriptorCache
 )
 
+// ValueDescriptorCache holds the value descriptors generated from the device resources
+// of the cached profiles, it is kept in sync with the profile cache.
 type ValueDescriptorCache interface {
-	ForName(name string) (models.ValueDescriptor, bool)
-	All() []models.ValueDescriptor
-	Add(descriptor models.ValueDescriptor) error
-	Update(descriptor models.ValueDescriptor) error
-	Remove(id string) error
-	RemoveByName(name string) error
+	ForName(profileName string, name string) (models.ValueDescriptor, bool)
+	ForProfile(profileName string) ([]models.ValueDescriptor, bool)
+	All() map[string][]models.ValueDescriptor
 }
 
 type valueDescriptorCache struct {
-	vdMap   map[string]models.ValueDescriptor // key is ValueDescriptor name
-	nameMap map[string]string                 // key is id, and value is ValueDescriptor name
-	mutex   sync.Mutex
+	vdMap map[string][]models.ValueDescriptor // key is DeviceProfile name, the descriptors are in resource order
+	mutex sync.Mutex
 }
 
-func (v *valueDescriptorCache) ForName(name string) (models.ValueDescriptor, bool) {
+// ForName returns the value descriptor of the device resource with the given name
+// of the given profile.
+func (v *valueDescriptorCache) ForName(profileName string, name string) (models.ValueDescriptor, bool) {
 	v.mutex.Lock()
 	defer v.mutex.Unlock()
 
-	vd, ok := v.vdMap[name]
-	return vd, ok
-}
-
-func (v *valueDescriptorCache) All() []models.ValueDescriptor {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	vds := make([]models.ValueDescriptor, len(v.vdMap))
-	i := 0
-	for _, vd := range v.vdMap {
-		vds[i] = vd
-		i++
+	for _, vd := range v.vdMap[profileName] {
+		if vd.Name == name {
+			return vd, true
+		}
 	}
-	return vds
+	return models.ValueDescriptor{}, false
 }
 
-func (v *valueDescriptorCache) Add(descriptor models.ValueDescriptor) error {
+// ForProfile returns the value descriptors of the given profile.
+func (v *valueDescriptorCache) ForProfile(profileName string) ([]models.ValueDescriptor, bool) {
 	v.mutex.Lock()
 	defer v.mutex.Unlock()
 
-	return v.add(descriptor)
-}
-
-func (v *valueDescriptorCache) add(descriptor models.ValueDescriptor) error {
-	_, ok := v.vdMap[descriptor.Name]
-	if ok {
-		return fmt.Errorf("value descriptor %s has already existed in cache", descriptor.Name)
+	vds, ok := v.vdMap[profileName]
+	if !ok {
+		return nil, false
 	}
-	v.vdMap[descriptor.Name] = descriptor
-	v.nameMap[descriptor.Id] = descriptor.Name
-	return nil
+	return append([]models.ValueDescriptor(nil), vds...), true
 }
 
-func (v *valueDescriptorCache) Update(descriptor models.ValueDescriptor) error {
+// All returns the value descriptors of all the cached profiles by profile name.
+func (v *valueDescriptorCache) All() map[string][]models.ValueDescriptor {
 	v.mutex.Lock()
 	defer v.mutex.Unlock()
 
-	if err := v.remove(descriptor.Id); err != nil {
-		return err
+	vds := make(map[string][]models.ValueDescriptor, len(v.vdMap))
+	for name, descriptors := range v.vdMap {
+		vds[name] = append([]models.ValueDescriptor(nil), descriptors...)
 	}
-	return v.add(descriptor)
+	return vds
 }
 
-func (v *valueDescriptorCache) Remove(id string) error {
+// generate replaces the value descriptors of the profile with the ones generated
+// from its device resources
+func (v *valueDescriptorCache) generate(profile models.DeviceProfile) {
 	v.mutex.Lock()
 	defer v.mutex.Unlock()
 
-	return v.remove(id)
+	v.vdMap[profile.Name] = descriptorsFromProfile(profile)
 }
 
-func (v *valueDescriptorCache) remove(id string) error {
-	name, ok := v.nameMap[id]
-	if !ok {
-		return fmt.Errorf("value descriptor %s does not exist in cache", id)
-	}
-
-	return v.removeByName(name)
-}
-
-func (v *valueDescriptorCache) RemoveByName(name string) error {
+func (v *valueDescriptorCache) remove(profileName string) {
 	v.mutex.Lock()
 	defer v.mutex.Unlock()
 
-	return v.removeByName(name)
+	delete(v.vdMap, profileName)
 }
 
-func (v *valueDescriptorCache) removeByName(name string) error {
-	vd, ok := v.vdMap[name]
-	if !ok {
-		return fmt.Errorf("value descriptor %s does not exist in cache", name)
+// descriptorsFromProfile generates a value descriptor for each device resource of
+// the profile. The type is normalized as it is in the readings, and the floats are
+// described with the encoding the readings use.
+func descriptorsFromProfile(profile models.DeviceProfile) []models.ValueDescriptor {
+	vds := make([]models.ValueDescriptor, len(profile.DeviceResources))
+	for i, dr := range profile.DeviceResources {
+		vd := models.From(dr)
+		if valueType, err := contracts.NormalizeValueType(vd.Type); err == nil {
+			vd.Type = valueType
+		}
+		if vd.Type == contracts.ValueTypeFloat32 || vd.Type == contracts.ValueTypeFloat64 {
+			vd.FloatEncoding = dsModels.DefaultFloatEncoding
+		}
+		if vd.Type != contracts.ValueTypeBinary {
+			vd.MediaType = ""
+		}
+		vd.Labels = profile.Labels
+		vds[i] = vd
 	}
-	delete(v.nameMap, vd.Id)
-	delete(v.vdMap, name)
-	return nil
+	return vds
 }
 
-func newValueDescriptorCache(descriptors []models.ValueDescriptor) ValueDescriptorCache {
-	defaultSize := len(descriptors) * 2
-	vdMap := make(map[string]models.ValueDescriptor, defaultSize)
-	nameMap := make(map[string]string, defaultSize)
-	for _, vd := range descriptors {
-		vdMap[vd.Name] = vd
-		nameMap[vd.Id] = vd.Name
+func newValueDescriptorCache(profiles []models.DeviceProfile) *valueDescriptorCache {
+	vdMap := make(map[string][]models.ValueDescriptor, len(profiles))
+	for _, dp := range profiles {
+		vdMap[dp.Name] = descriptorsFromProfile(dp)
 	}
-	vdc = &valueDescriptorCache{vdMap: vdMap, nameMap: nameMap}
+	vdc = &valueDescriptorCache{vdMap: vdMap}
 	return vdc
 }
 
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+func descriptorProfile(id string, resources ...models.DeviceResource) models.DeviceProfile {
+	return models.DeviceProfile{Id: id, Name: "profile-" + id, Labels: []string{"meter"}, DeviceResources: resources}
+}
+
+func TestDescriptorsFromProfile(t *testing.T) {
+	profile := descriptorProfile("1",
+		models.DeviceResource{Name: "temperature", Description: "room temperature", Properties: models.PropertyValue{
+			Type: "float32", Minimum: "-40", Maximum: "85", DefaultValue: "20", Units: "degC", MediaType: "text/plain"}},
+		models.DeviceResource{Name: "image", Properties: models.PropertyValue{Type: contracts.ValueTypeBinary, MediaType: "image/jpeg"}},
+	)
+
+	vds := descriptorsFromProfile(profile)
+	require.Len(t, vds, 2)
+	assert.Equal(t, "temperature", vds[0].Name)
+	assert.Equal(t, "room temperature", vds[0].Description)
+	assert.Equal(t, contracts.ValueTypeFloat32, vds[0].Type)
+	assert.Equal(t, "-40", vds[0].Min)
+	assert.Equal(t, "85", vds[0].Max)
+	assert.Equal(t, "20", vds[0].DefaultValue)
+	assert.Equal(t, "degC", vds[0].UomLabel)
+	assert.Equal(t, dsModels.DefaultFloatEncoding, vds[0].FloatEncoding)
+	assert.Empty(t, vds[0].MediaType)
+	assert.Equal(t, []string{"meter"}, vds[0].Labels)
+	assert.Equal(t, "image/jpeg", vds[1].MediaType)
+	assert.Empty(t, vds[1].FloatEncoding)
+}
+
+func TestValueDescriptorCache_FollowsProfiles(t *testing.T) {
+	dpc := newProfileCache([]models.DeviceProfile{descriptorProfile("1",
+		models.DeviceResource{Name: "voltage", Properties: models.PropertyValue{Type: contracts.ValueTypeInt32, ReadWrite: "R"}})})
+
+	vd, ok := ValueDescriptors().ForName("profile-1", "voltage")
+	assert.True(t, ok)
+	assert.Equal(t, contracts.ValueTypeInt32, vd.Type)
+	_, ok = ValueDescriptors().ForName("profile-1", "current")
+	assert.False(t, ok)
+
+	updated := descriptorProfile("1",
+		models.DeviceResource{Name: "voltage", Properties: models.PropertyValue{Type: contracts.ValueTypeInt32, ReadWrite: "R"}},
+		models.DeviceResource{Name: "current", Properties: models.PropertyValue{Type: contracts.ValueTypeInt32, ReadWrite: "R"}})
+	require.NoError(t, dpc.Update(updated))
+	vds, ok := ValueDescriptors().ForProfile("profile-1")
+	assert.True(t, ok)
+	assert.Len(t, vds, 2)
+
+	require.NoError(t, dpc.Add(descriptorProfile("2")))
+	assert.Len(t, ValueDescriptors().All(), 2)
+
+	require.NoError(t, dpc.RemoveByName("profile-1"))
+	_, ok = ValueDescriptors().ForProfile("profile-1")
+	assert.False(t, ok)
+	assert.Len(t, ValueDescriptors().All(), 1)
+}
@@ -9,6 +9,10 @@ package callback
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
@@ -16,30 +20,85 @@ import (
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autodiscovery"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autoevent"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
 	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
 )
 
-func UpdateProfile(profileRequest requests.DeviceProfileRequest, lc logger.LoggingClient) errors.EdgeX {
-	_, ok := cache.Profiles().ForName(profileRequest.Profile.Name)
-	if !ok {
-		errMsg := fmt.Sprintf("failed to find profile %s", profileRequest.Profile.Name)
+// callbackMutex serialises the callbacks and the reconciliations, so that a
+// reconciliation does not revert the changes of a callback received meanwhile
+var callbackMutex sync.Mutex
+
+// UpdateProfile updates the profile in cache, notifies the driver if it implements
+// ProfileAwareDriver and restarts the AutoEvents reading the changed resources.
+// A profile with problems is rejected and the cached profile is kept. The cached
+// profiles extending the profile are flattened again with its new version.
+func UpdateProfile(profileRequest requests.DeviceProfileRequest, dic *di.Container) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return updateProfile(profileRequest, dic)
+}
+
+func updateProfile(profileRequest requests.DeviceProfileRequest, dic *di.Container) errors.EdgeX {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	name := profileRequest.Profile.Name
+
+	_, cached := cache.Profiles().ForName(name)
+	extending := cache.Profiles().Extending(name)
+	if !cached && len(extending) == 0 {
+		errMsg := fmt.Sprintf("failed to find profile %s", name)
 		return errors.NewCommonEdgeX(errors.KindInvalidId, errMsg, nil)
 	}
 
-	err := cache.Profiles().Update(dtos.ToDeviceProfileModel(profileRequest.Profile))
+	if cached {
+		if err := updateCachedProfile(dtos.ToDeviceProfileModel(profileRequest.Profile), dic); err != nil {
+			return err
+		}
+	}
+
+	dpc := container.MetadataDeviceProfileClientFrom(dic.Get)
+	for _, derived := range extending {
+		res, err := dpc.DeviceProfileByName(context.Background(), derived)
+		if err != nil {
+			lc.Error(fmt.Sprintf("failed to get profile %s extending profile %s: %v", derived, name, err))
+			continue
+		}
+		if err := updateCachedProfile(dtos.ToDeviceProfileModel(res.Profile), dic); err != nil {
+			lc.Error(fmt.Sprintf("failed to update profile %s extending profile %s: %v", derived, name, err))
+		}
+	}
+	return nil
+}
+
+func updateCachedProfile(profile models.DeviceProfile, dic *di.Container) errors.EdgeX {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+
+	previous, _ := cache.Profiles().ForName(profile.Name)
+	err := cache.Profiles().Update(profile)
 	if err != nil {
-		errMsg := fmt.Sprintf("failed to update profile %s", profileRequest.Profile.Name)
-		return errors.NewCommonEdgeX(errors.KindServerError, errMsg, err)
+		errMsg := fmt.Sprintf("failed to update profile %s", profile.Name)
+		return errors.NewCommonEdgeX(errors.Kind(err), errMsg, err)
 	}
+	lc.Debug(fmt.Sprintf("profile %s updated", profile.Name))
 
-	lc.Debug(fmt.Sprintf("profile %s updated", profileRequest.Profile.Name))
+	// the cache holds the profile flattened with its base profiles
+	profile, _ = cache.Profiles().ForName(profile.Name)
+	notifyProfileUpdate(profile, dic)
+	restartAffectedAutoEvents(previous, profile, dic)
 	return nil
 }
 
 func AddDevice(addDeviceRequest requests.AddDeviceRequest, dic *di.Container) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return addDevice(addDeviceRequest, dic)
+}
+
+func addDevice(addDeviceRequest requests.AddDeviceRequest, dic *di.Container) errors.EdgeX {
 	device := dtos.ToDeviceModel(addDeviceRequest.Device)
 	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
 
@@ -71,6 +130,12 @@ func AddDevice(addDeviceRequest requests.AddDeviceRequest, dic *di.Container) er
 }
 
 func UpdateDevice(updateDeviceRequest requests.UpdateDeviceRequest, dic *di.Container) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return updateDevice(updateDeviceRequest, dic)
+}
+
+func updateDevice(updateDeviceRequest requests.UpdateDeviceRequest, dic *di.Container) errors.EdgeX {
 	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
 
 	device, ok := cache.Devices().ForName(*updateDeviceRequest.Device.Name)
@@ -79,6 +144,7 @@ func UpdateDevice(updateDeviceRequest requests.UpdateDeviceRequest, dic *di.Cont
 		return errors.NewCommonEdgeX(errors.KindInvalidId, errMsg, nil)
 	}
 
+	previousProfileName := device.ProfileName
 	requests.ReplaceDeviceModelFieldsWithDTO(&device, updateDeviceRequest.Device)
 	// TODO: uncomment when core-contracts v2 client is ready.
 	edgexErr := updateAssociatedProfile(device.ProfileName, dic)
@@ -103,12 +169,24 @@ func UpdateDevice(updateDeviceRequest requests.UpdateDeviceRequest, dic *di.Cont
 		return errors.NewCommonEdgeX(errors.KindServerError, errMsg, err)
 	}
 
+	if device.ProfileName != previousProfileName {
+		if profile, ok := cache.Profiles().ForName(device.ProfileName); ok {
+			notifyProfileUpdate(profile, dic)
+		}
+	}
+
 	lc.Debug(fmt.Sprintf("Handler - starting AutoEvents for device %s", device.Name))
 	autoevent.GetManager().RestartForDevice(device.Name, dic)
 	return nil
 }
 
 func DeleteDevice(name string, dic *di.Container) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return deleteDevice(name, dic)
+}
+
+func deleteDevice(name string, dic *di.Container) errors.EdgeX {
 	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
 	// check the device exist and stop its autoevents
 	device, ok := cache.Devices().ForName(name)
@@ -153,6 +231,12 @@ func DeleteDevice(name string, dic *di.Container) errors.EdgeX {
 }
 
 func AddProvisionWatcher(addProvisionWatcherRequest requests.AddProvisionWatcherRequest, lc logger.LoggingClient) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return addProvisionWatcher(addProvisionWatcherRequest, lc)
+}
+
+func addProvisionWatcher(addProvisionWatcherRequest requests.AddProvisionWatcherRequest, lc logger.LoggingClient) errors.EdgeX {
 	provisionWatcher := dtos.ToProvisionWatcherModel(addProvisionWatcherRequest.ProvisionWatcher)
 
 	edgexErr := cache.ProvisionWatchers().Add(provisionWatcher)
@@ -166,6 +250,12 @@ func AddProvisionWatcher(addProvisionWatcherRequest requests.AddProvisionWatcher
 }
 
 func UpdateProvisionWatcher(updateProvisionWatcherRequest requests.UpdateProvisionWatcherRequest, lc logger.LoggingClient) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return updateProvisionWatcher(updateProvisionWatcherRequest, lc)
+}
+
+func updateProvisionWatcher(updateProvisionWatcherRequest requests.UpdateProvisionWatcherRequest, lc logger.LoggingClient) errors.EdgeX {
 	provisionWatcher, ok := cache.ProvisionWatchers().ForName(*updateProvisionWatcherRequest.ProvisionWatcher.Name)
 	if !ok {
 		errMsg := fmt.Sprintf("failed to find provision watcher %s", *updateProvisionWatcherRequest.ProvisionWatcher.Name)
@@ -185,6 +275,12 @@ func UpdateProvisionWatcher(updateProvisionWatcherRequest requests.UpdateProvisi
 }
 
 func DeleteProvisionWatcher(name string, lc logger.LoggingClient) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return deleteProvisionWatcher(name, lc)
+}
+
+func deleteProvisionWatcher(name string, lc logger.LoggingClient) errors.EdgeX {
 	err := cache.ProvisionWatchers().RemoveByName(name)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to remove provision watcher %s", name)
@@ -195,36 +291,122 @@ func DeleteProvisionWatcher(name string, lc logger.LoggingClient) errors.EdgeX {
 	return nil
 }
 
+// UpdateDeviceService applies the changes of the Device Service made in Core Metadata
+// to the in-memory Device Service, and pauses or resumes the AutoEvents and the
+// discovery when the Device Service gets locked or unlocked.
+func UpdateDeviceService(updateDeviceServiceRequest requests.UpdateDeviceServiceRequest, dic *di.Container) errors.EdgeX {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+	return updateDeviceService(updateDeviceServiceRequest, dic)
+}
+
+func updateDeviceService(updateDeviceServiceRequest requests.UpdateDeviceServiceRequest, dic *di.Container) errors.EdgeX {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	ds := container.DeviceServiceFrom(dic.Get)
+
+	patch := updateDeviceServiceRequest.Service
+	if (patch.Id == nil || *patch.Id != ds.Id) && (patch.Name == nil || *patch.Name != ds.Name) {
+		errMsg := fmt.Sprintf("failed to find device service %s", ds.Name)
+		return errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, errMsg, nil)
+	}
+
+	previous := ds
+	requests.ReplaceDeviceServiceModelFieldsWithDTO(&ds, patch)
+	dic.Update(di.ServiceConstructorMap{
+		container.DeviceServiceName: func(get di.Get) interface{} {
+			return ds
+		},
+	})
+	lc.Debugf("device service %s updated", ds.Name)
+
+	labelsChanged := !reflect.DeepEqual(previous.Labels, ds.Labels)
+	addressChanged := previous.BaseAddress != ds.BaseAddress
+	if labelsChanged || addressChanged {
+		// keep the configuration consistent, the labels and the address are sent to
+		// metadata when the service registers at startup. The configuration is shared,
+		// so it is copied rather than modified.
+		configuration := *container.ConfigurationFrom(dic.Get)
+		if labelsChanged {
+			configuration.Service.Labels = ds.Labels
+			lc.Infof("device service %s labels changed to %v", ds.Name, ds.Labels)
+		}
+		if addressChanged {
+			if err := applyBaseAddress(&configuration.Service, ds.BaseAddress); err != nil {
+				lc.Errorf("device service %s base address %s not applied: %v", ds.Name, ds.BaseAddress, err)
+			} else {
+				lc.Infof("device service %s base address changed from %s to %s", ds.Name, previous.BaseAddress, ds.BaseAddress)
+			}
+		}
+		dic.Update(di.ServiceConstructorMap{
+			container.ConfigurationName: func(get di.Get) interface{} {
+				return &configuration
+			},
+		})
+	}
+
+	if previous.AdminState != ds.AdminState {
+		if ds.AdminState == models.Locked {
+			lc.Infof("Handler - stopping AutoEvents for locked device service %s", ds.Name)
+			autoevent.GetManager().StopAutoEvents()
+			if job, ok := autodiscovery.CancelRunningJob(); ok {
+				lc.Infof("Handler - discovery job %s cancelled for locked device service %s", job.Id, ds.Name)
+			}
+		} else {
+			lc.Infof("Handler - starting AutoEvents for unlocked device service %s", ds.Name)
+			autoevent.GetManager().StartAutoEvents(dic)
+		}
+	}
+
+	return nil
+}
+
+// applyBaseAddress sets the protocol, host and port of the service to the ones of
+// the base address, such as http://192.168.1.5:49990
+func applyBaseAddress(service *common.ServiceInfo, baseAddress string) error {
+	u, err := url.Parse(baseAddress)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Hostname() == "" || u.Port() == "" {
+		return fmt.Errorf("protocol, host and port are required")
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return err
+	}
+	service.Protocol = u.Scheme
+	service.Host = u.Hostname()
+	service.Port = port
+	return nil
+}
+
 // updateAssociatedProfile updates the profile specified in AddDeviceRequest or UpdateDeviceRequest
 // to stay consistent with core metadata.
 func updateAssociatedProfile(profileName string, dic *di.Container) errors.EdgeX {
-	// TODO: uncomment when core-contracts v2 clients are ready.
 	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
-	//gc := container.GeneralClientFrom(dic.Get)
-	//vdc := container.CoredataValueDescriptorClientFrom(dic.Get)
 	dpc := container.MetadataDeviceProfileClientFrom(dic.Get)
-	lc.Debugf("get profile: ", profileName)
+	lc.Debugf("get profile: %s", profileName)
 
 	resp, err := dpc.DeviceProfileByName(context.Background(), profileName)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to find profile %s in metadata", profileName)
 		return errors.NewCommonEdgeX(errors.KindInvalidId, errMsg, nil)
 	}
-	fmt.Printf("%+v\n", resp)
 	_, exist := cache.Profiles().ForName(profileName)
 	if exist == false {
 		err = cache.Profiles().Add(dtos.ToDeviceProfileModel(resp.Profile))
 		if err == nil {
-			//provision.CreateDescriptorsFromProfile(&profile, lc, gc, vdc)
-			//lc.Info(fmt.Sprintf("Added device profile: %s", profileName))
+			lc.Info(fmt.Sprintf("Added device profile %s and its value descriptors", profileName))
 		} else {
 			errMsg := fmt.Sprintf("failed to add profile %s", profileName)
 			return errors.NewCommonEdgeX(errors.KindServerError, errMsg, err)
 		}
 	} else {
-		err := cache.Profiles().Update(dtos.ToDeviceProfileModel(resp.Profile))
+		// as the profile callback does, the driver is notified and the AutoEvents of
+		// the other devices reading changed resources are restarted
+		err := updateCachedProfile(dtos.ToDeviceProfileModel(resp.Profile), dic)
 		if err != nil {
-			lc.Warn(fmt.Sprintf("failed to to update profile %s in cache, using the original one", profileName))
+			lc.Warn(fmt.Sprintf("failed to update profile %s in cache, using the original one: %v", profileName, err))
 		}
 	}
 
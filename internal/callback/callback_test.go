@@ -0,0 +1,61 @@
+package callback
+
+import (
+	"testing"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+)
+
+func TestUpdateDeviceService(t *testing.T) {
+	config := &common.ConfigurationStruct{}
+	config.Service = common.ServiceInfo{Protocol: "http", Host: "localhost", Port: 49990, Labels: []string{"old"}}
+	dic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return logger.NewMockClient()
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+		container.DeviceServiceName: func(get di.Get) interface{} {
+			return models.DeviceService{Id: "id", Name: "service", BaseAddress: "http://localhost:49990", Labels: []string{"old"}}
+		},
+	})
+
+	name := "service"
+	address := "https://192.168.1.5:49991"
+	err := UpdateDeviceService(requests.UpdateDeviceServiceRequest{Service: dtos.UpdateDeviceService{
+		Name:        &name,
+		BaseAddress: &address,
+		Labels:      []string{"new"},
+	}}, dic)
+	require.NoError(t, err)
+
+	ds := container.DeviceServiceFrom(dic.Get)
+	assert.Equal(t, address, ds.BaseAddress)
+	assert.Equal(t, []string{"new"}, ds.Labels)
+
+	updated := container.ConfigurationFrom(dic.Get)
+	assert.Equal(t, common.ServiceInfo{Protocol: "https", Host: "192.168.1.5", Port: 49991, Labels: []string{"new"}}, updated.Service)
+	assert.Equal(t, []string{"old"}, config.Service.Labels, "the shared configuration should not be modified")
+	assert.Equal(t, "localhost", config.Service.Host, "the shared configuration should not be modified")
+}
+
+func TestApplyBaseAddress(t *testing.T) {
+	service := common.ServiceInfo{Protocol: "http", Host: "localhost", Port: 49990}
+	assert.Error(t, applyBaseAddress(&service, "localhost"))
+	assert.Error(t, applyBaseAddress(&service, "http://localhost"))
+	assert.Equal(t, common.ServiceInfo{Protocol: "http", Host: "localhost", Port: 49990}, service)
+
+	require.NoError(t, applyBaseAddress(&service, "http://10.0.0.1:50000"))
+	assert.Equal(t, common.ServiceInfo{Protocol: "http", Host: "10.0.0.1", Port: 50000}, service)
+}
@@ -0,0 +1,127 @@
+package callback
+
+import (
+	"fmt"
+	"reflect"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autoevent"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// notifyProfileUpdate passes the profile to the driver if it implements ProfileAwareDriver
+func notifyProfileUpdate(profile models.DeviceProfile, dic *di.Container) {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	driver, ok := container.ProtocolDriverFrom(dic.Get).(dsModels.ProfileAwareDriver)
+	if !ok {
+		return
+	}
+
+	if err := driver.UpdateProfile(profile); err != nil {
+		lc.Error(fmt.Sprintf("driver.UpdateProfile callback failed for %s: %v", profile.Name, err))
+		return
+	}
+	lc.Debug(fmt.Sprintf("Invoked driver.UpdateProfile callback for %s", profile.Name))
+}
+
+// restartAffectedAutoEvents restarts the AutoEvents of the devices using the profile
+// which read a device resource or command changed between the two profile versions
+func restartAffectedAutoEvents(previous, profile models.DeviceProfile, dic *di.Container) {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	changed := changedResources(previous, profile)
+	if len(changed) == 0 {
+		return
+	}
+	tags := changedTags(previous, profile, changed)
+
+	for _, d := range cache.Devices().All() {
+		if d.ProfileName != profile.Name || !readsAny(d.AutoEvents, changed, tags) {
+			continue
+		}
+		lc.Debug(fmt.Sprintf("Handler - restarting AutoEvents of device %s for updated profile %s", d.Name, profile.Name))
+		autoevent.GetManager().RestartForDevice(d.Name, dic)
+	}
+}
+
+// changedResources returns the names of the device resources and device commands
+// which are different, added or removed in the new profile, and of the device
+// commands reading or writing a changed device resource
+func changedResources(previous, profile models.DeviceProfile) map[string]bool {
+	changed := make(map[string]bool)
+
+	resources := make(map[string]models.DeviceResource, len(previous.DeviceResources))
+	for _, r := range previous.DeviceResources {
+		resources[r.Name] = r
+	}
+	for _, r := range profile.DeviceResources {
+		if old, ok := resources[r.Name]; !ok || !reflect.DeepEqual(old, r) {
+			changed[r.Name] = true
+		}
+		delete(resources, r.Name)
+	}
+	for name := range resources {
+		changed[name] = true
+	}
+
+	commands := make(map[string]models.ProfileResource, len(previous.DeviceCommands))
+	for _, c := range previous.DeviceCommands {
+		commands[c.Name] = c
+	}
+	for _, c := range profile.DeviceCommands {
+		if old, ok := commands[c.Name]; !ok || !reflect.DeepEqual(old, c) {
+			changed[c.Name] = true
+		}
+		delete(commands, c.Name)
+	}
+	for name := range commands {
+		changed[name] = true
+	}
+
+	for _, p := range []models.DeviceProfile{previous, profile} {
+		for _, c := range p.DeviceCommands {
+			if usesAny(c, changed) {
+				changed[c.Name] = true
+			}
+		}
+	}
+
+	return changed
+}
+
+func usesAny(command models.ProfileResource, resources map[string]bool) bool {
+	for _, ops := range [][]models.ResourceOperation{command.Get, command.Set} {
+		for _, op := range ops {
+			if resources[op.DeviceResource] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changedTags returns the tags of the changed device resources, in either profile
+func changedTags(previous, profile models.DeviceProfile, changed map[string]bool) map[string]bool {
+	tags := make(map[string]bool)
+	for _, p := range []models.DeviceProfile{previous, profile} {
+		for _, r := range p.DeviceResources {
+			if r.Tag != "" && changed[r.Name] {
+				tags[r.Tag] = true
+			}
+		}
+	}
+	return tags
+}
+
+func readsAny(autoEvents []models.AutoEvent, resources map[string]bool, tags map[string]bool) bool {
+	for _, a := range autoEvents {
+		if a.Tag != "" && tags[a.Tag] || a.Tag == "" && resources[a.Resource] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package callback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+func TestChangedResources(t *testing.T) {
+	previous := models.DeviceProfile{
+		Name: "profile",
+		DeviceResources: []models.DeviceResource{
+			{Name: "temperature", Attributes: map[string]string{"register": "1"}},
+			{Name: "humidity", Attributes: map[string]string{"register": "2"}},
+			{Name: "removed"},
+		},
+		DeviceCommands: []models.ProfileResource{
+			{Name: "all", Get: []models.ResourceOperation{{DeviceResource: "temperature"}}},
+		},
+	}
+	profile := models.DeviceProfile{
+		Name: "profile",
+		DeviceResources: []models.DeviceResource{
+			{Name: "temperature", Attributes: map[string]string{"register": "1"}},
+			{Name: "humidity", Attributes: map[string]string{"register": "3"}},
+			{Name: "added"},
+		},
+		DeviceCommands: []models.ProfileResource{
+			{Name: "all", Get: []models.ResourceOperation{{DeviceResource: "temperature"}, {DeviceResource: "humidity"}}},
+		},
+	}
+
+	changed := changedResources(previous, profile)
+	assert.Equal(t, map[string]bool{"humidity": true, "removed": true, "added": true, "all": true}, changed)
+	assert.Empty(t, changedResources(profile, profile))
+
+	assert.True(t, readsAny([]models.AutoEvent{{Resource: "temperature"}, {Resource: "all"}}, changed, nil))
+	assert.False(t, readsAny([]models.AutoEvent{{Resource: "temperature"}}, changed, nil))
+}
+
+func TestChangedResourcesExpandsToCommands(t *testing.T) {
+	previous := models.DeviceProfile{
+		Name: "profile",
+		DeviceResources: []models.DeviceResource{
+			{Name: "temperature", Tag: "climate", Attributes: map[string]string{"register": "1"}},
+			{Name: "power", Tag: "energy"},
+		},
+		DeviceCommands: []models.ProfileResource{
+			{Name: "climate", Get: []models.ResourceOperation{{DeviceResource: "temperature"}}},
+			{Name: "setpoint", Set: []models.ResourceOperation{{DeviceResource: "temperature"}}},
+			{Name: "energy", Get: []models.ResourceOperation{{DeviceResource: "power"}}},
+		},
+	}
+	profile := previous
+	profile.DeviceResources = []models.DeviceResource{
+		{Name: "temperature", Tag: "climate", Attributes: map[string]string{"register": "2"}},
+		{Name: "power", Tag: "energy"},
+	}
+
+	changed := changedResources(previous, profile)
+	assert.Equal(t, map[string]bool{"temperature": true, "climate": true, "setpoint": true}, changed)
+	tags := changedTags(previous, profile, changed)
+	assert.Equal(t, map[string]bool{"climate": true}, tags)
+
+	// an AutoEvent reading a command whose device resource changed is affected
+	assert.True(t, readsAny([]models.AutoEvent{{Resource: "climate"}}, changed, tags))
+	assert.False(t, readsAny([]models.AutoEvent{{Resource: "energy"}}, changed, tags))
+	assert.True(t, readsAny([]models.AutoEvent{{Tag: "climate"}}, changed, tags))
+	assert.False(t, readsAny([]models.AutoEvent{{Tag: "energy"}}, changed, tags))
+}
@@ -0,0 +1,369 @@
+package callback
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/google/uuid"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	commonDTO "github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+)
+
+// Drift counts the differences between the caches and Core Metadata found, and
+// fixed, by a reconciliation
+type Drift struct {
+	DevicesAdded    uint64
+	DevicesUpdated  uint64
+	DevicesRemoved  uint64
+	ProfilesUpdated uint64
+	ProfilesRemoved uint64
+	WatchersAdded   uint64
+	WatchersUpdated uint64
+	WatchersRemoved uint64
+}
+
+// Total returns the number of differences
+func (d Drift) Total() uint64 {
+	return d.DevicesAdded + d.DevicesUpdated + d.DevicesRemoved + d.ProfilesUpdated + d.ProfilesRemoved +
+		d.WatchersAdded + d.WatchersUpdated + d.WatchersRemoved
+}
+
+func (d Drift) counts() map[string]uint64 {
+	return map[string]uint64{
+		"devicesAdded":    d.DevicesAdded,
+		"devicesUpdated":  d.DevicesUpdated,
+		"devicesRemoved":  d.DevicesRemoved,
+		"profilesUpdated": d.ProfilesUpdated,
+		"profilesRemoved": d.ProfilesRemoved,
+		"watchersAdded":   d.WatchersAdded,
+		"watchersUpdated": d.WatchersUpdated,
+		"watchersRemoved": d.WatchersRemoved,
+	}
+}
+
+func (d *Drift) add(other Drift) {
+	d.DevicesAdded += other.DevicesAdded
+	d.DevicesUpdated += other.DevicesUpdated
+	d.DevicesRemoved += other.DevicesRemoved
+	d.ProfilesUpdated += other.ProfilesUpdated
+	d.ProfilesRemoved += other.ProfilesRemoved
+	d.WatchersAdded += other.WatchersAdded
+	d.WatchersUpdated += other.WatchersUpdated
+	d.WatchersRemoved += other.WatchersRemoved
+}
+
+var (
+	driftMutex sync.Mutex
+	totalDrift *Drift
+)
+
+// DriftCounts returns the differences fixed by all the reconciliations since the
+// service started, or nil if the reconciler is not running
+func DriftCounts() map[string]uint64 {
+	driftMutex.Lock()
+	defer driftMutex.Unlock()
+
+	if totalDrift == nil {
+		return nil
+	}
+	return totalDrift.counts()
+}
+
+// RunReconciler reconciles the caches with Core Metadata every interval until ctx
+// is done, so that a missed callback does not leave the service out of sync.
+func RunReconciler(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, dic *di.Container) {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	driftMutex.Lock()
+	totalDrift = &Drift{}
+	driftMutex.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				drift, err := Reconcile(ctx, dic)
+				if err != nil {
+					lc.Error(fmt.Sprintf("cache reconciliation failed: %v", err))
+				}
+				if drift.Total() > 0 {
+					lc.Warn(fmt.Sprintf("cache reconciliation fixed %d differences with metadata: %+v", drift.Total(), drift))
+				}
+				driftMutex.Lock()
+				totalDrift.add(drift)
+				driftMutex.Unlock()
+			}
+		}
+	}()
+}
+
+// ReconcileWhenAvailable retries every interval to reconcile the caches with Core
+// Metadata until it succeeds once. It is used when metadata was unreachable at
+// startup, the caches being restored from a snapshot or empty. The snapshot is
+// written again once the reconciliation succeeded.
+func ReconcileWhenAvailable(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, dic *di.Container) {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				drift, err := Reconcile(ctx, dic)
+				if err != nil {
+					lc.Debug(fmt.Sprintf("metadata still unavailable, caches not reconciled: %v", err))
+					continue
+				}
+				lc.Info(fmt.Sprintf("caches reconciled with metadata available again, %d differences fixed: %+v", drift.Total(), drift))
+				cache.MetadataLoaded()
+				return
+			}
+		}
+	}()
+}
+
+// Reconcile diffs the device, profile and provision watcher caches against Core
+// Metadata and applies the differences through the callback handlers, which notify
+// the driver and restart the AutoEvents as the callbacks do. Each cache is reconciled
+// even if another one cannot be. Failing changes are logged and retried by the next
+// reconciliation. The callbacks received meanwhile wait for the reconciliation.
+func Reconcile(ctx context.Context, dic *di.Container) (Drift, errors.EdgeX) {
+	callbackMutex.Lock()
+	defer callbackMutex.Unlock()
+
+	var drift Drift
+	serviceName := container.DeviceServiceFrom(dic.Get).Name
+	ctx = context.WithValue(ctx, common.CorrelationHeader, uuid.New().String())
+
+	devicesErr := reconcileDevices(ctx, serviceName, dic, &drift)
+	reconcileProfiles(ctx, dic, &drift)
+	watchersErr := reconcileProvisionWatchers(ctx, serviceName, dic, &drift)
+
+	switch {
+	case devicesErr != nil && watchersErr != nil:
+		errMsg := fmt.Sprintf("%s; %s", devicesErr.Message(), watchersErr.Message())
+		return drift, errors.NewCommonEdgeX(errors.Kind(devicesErr), errMsg, devicesErr)
+	case devicesErr != nil:
+		return drift, devicesErr
+	case watchersErr != nil:
+		return drift, watchersErr
+	}
+	return drift, nil
+}
+
+// reconcileDevices adds, updates and removes the cached devices changed in metadata
+func reconcileDevices(ctx context.Context, serviceName string, dic *di.Container, drift *Drift) errors.EdgeX {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	devicesResponse, err := container.MetadataDeviceClientFrom(dic.Get).DevicesByServiceName(ctx, serviceName, 0, -1)
+	if err != nil {
+		return errors.NewCommonEdgeX(errors.Kind(err), "failed to get the devices from metadata", err)
+	}
+	current := make([]models.Device, len(devicesResponse.Devices))
+	for i, d := range devicesResponse.Devices {
+		current[i] = dtos.ToDeviceModel(d)
+	}
+	added, updated, removed := diffDevices(cache.Devices().All(), current)
+	for _, d := range added {
+		req := requests.AddDeviceRequest{BaseRequest: commonDTO.NewBaseRequest(), Device: dtos.FromDeviceModelToDTO(d)}
+		if err := addDevice(req, dic); err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to add device %s: %v", d.Name, err))
+			continue
+		}
+		drift.DevicesAdded++
+	}
+	for _, patch := range updated {
+		req := requests.UpdateDeviceRequest{BaseRequest: commonDTO.NewBaseRequest(), Device: patch}
+		if err := updateDevice(req, dic); err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to update device %s: %v", *patch.Name, err))
+			continue
+		}
+		drift.DevicesUpdated++
+	}
+	for _, name := range removed {
+		if err := deleteDevice(name, dic); err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to remove device %s: %v", name, err))
+			continue
+		}
+		drift.DevicesRemoved++
+	}
+	return nil
+}
+
+// reconcileProvisionWatchers adds, updates and removes the cached provision watchers
+// changed in metadata
+func reconcileProvisionWatchers(ctx context.Context, serviceName string, dic *di.Container, drift *Drift) errors.EdgeX {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	watchersResponse, err := container.MetadataProvisionWatcherClientFrom(dic.Get).ProvisionWatchersByServiceName(ctx, serviceName, 0, -1)
+	if err != nil {
+		return errors.NewCommonEdgeX(errors.Kind(err), "failed to get the provision watchers from metadata", err)
+	}
+	currentWatchers := make([]models.ProvisionWatcher, len(watchersResponse.ProvisionWatchers))
+	for i, pw := range watchersResponse.ProvisionWatchers {
+		currentWatchers[i] = dtos.ToProvisionWatcherModel(pw)
+	}
+	addedWatchers, updatedWatchers, removedWatchers := diffProvisionWatchers(cache.ProvisionWatchers().All(), currentWatchers)
+	for _, pw := range addedWatchers {
+		req := requests.AddProvisionWatcherRequest{BaseRequest: commonDTO.NewBaseRequest(), ProvisionWatcher: dtos.FromProvisionWatcherModelToDTO(pw)}
+		if err := addProvisionWatcher(req, lc); err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to add provision watcher %s: %v", pw.Name, err))
+			continue
+		}
+		drift.WatchersAdded++
+	}
+	for _, patch := range updatedWatchers {
+		req := requests.UpdateProvisionWatcherRequest{BaseRequest: commonDTO.NewBaseRequest(), ProvisionWatcher: patch}
+		if err := updateProvisionWatcher(req, lc); err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to update provision watcher %s: %v", *patch.Name, err))
+			continue
+		}
+		drift.WatchersUpdated++
+	}
+	for _, name := range removedWatchers {
+		if err := deleteProvisionWatcher(name, lc); err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to remove provision watcher %s: %v", name, err))
+			continue
+		}
+		drift.WatchersRemoved++
+	}
+	return nil
+}
+
+// reconcileProfiles updates the cached profiles changed in metadata and removes the
+// unused ones deleted from metadata. The profiles of the added devices are cached by
+// AddDevice.
+func reconcileProfiles(ctx context.Context, dic *di.Container, drift *Drift) {
+	lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+	dpc := container.MetadataDeviceProfileClientFrom(dic.Get)
+
+	for _, cached := range cache.Profiles().All() {
+		res, err := dpc.DeviceProfileByName(ctx, cached.Name)
+		if errors.Kind(err) == errors.KindEntityDoesNotExist {
+			if !cache.CheckProfileNotUsed(cached.Name) {
+				lc.Warn(fmt.Sprintf("profile %s deleted from metadata is still used by devices", cached.Name))
+				continue
+			}
+			if err := cache.Profiles().RemoveByName(cached.Name); err != nil {
+				lc.Error(fmt.Sprintf("reconciliation failed to remove profile %s: %v", cached.Name, err))
+				continue
+			}
+			drift.ProfilesRemoved++
+			continue
+		} else if err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to get profile %s: %v", cached.Name, err))
+			continue
+		}
+
+		// the cached profile is flattened with its base profiles
+		current, err := cache.FlattenProfile(dtos.ToDeviceProfileModel(res.Profile), cache.MetadataProfiles(dpc))
+		if err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to resolve profile %s: %v", cached.Name, err))
+			continue
+		}
+		if reflect.DeepEqual(cached, current) {
+			continue
+		}
+		req := requests.DeviceProfileRequest{BaseRequest: commonDTO.NewBaseRequest(), Profile: res.Profile}
+		if err := updateProfile(req, dic); err != nil {
+			lc.Error(fmt.Sprintf("reconciliation failed to update profile %s: %v", cached.Name, err))
+			continue
+		}
+		drift.ProfilesUpdated++
+	}
+}
+
+// diffDevices returns the devices to add to the cache, the patches of the cached
+// devices changed in metadata and the names of the devices to remove from the cache.
+// LastConnected and LastReported are not compared as they change all the time, nor is
+// OperatingState as the cached one is authoritative: the service sets it locally, such
+// as DOWN when the AutoEvents of a device keep failing, and metadata may lag behind.
+func diffDevices(cached, current []models.Device) ([]models.Device, []dtos.UpdateDevice, []string) {
+	var added []models.Device
+	var updated []dtos.UpdateDevice
+	var removed []string
+
+	cachedByName := make(map[string]models.Device, len(cached))
+	for _, d := range cached {
+		cachedByName[d.Name] = d
+	}
+	currentNames := make(map[string]bool, len(current))
+	for _, d := range current {
+		currentNames[d.Name] = true
+		c, ok := cachedByName[d.Name]
+		if !ok {
+			added = append(added, d)
+			continue
+		}
+		patch := dtos.FromDeviceModelToUpdateDTO(d)
+		patch.LastConnected, patch.LastReported, patch.OperatingState = nil, nil, nil
+		patched := c
+		requests.ReplaceDeviceModelFieldsWithDTO(&patched, patch)
+		if !reflect.DeepEqual(dtos.FromDeviceModelToUpdateDTO(patched), dtos.FromDeviceModelToUpdateDTO(c)) {
+			updated = append(updated, patch)
+		}
+	}
+	for _, d := range cached {
+		if !currentNames[d.Name] {
+			removed = append(removed, d.Name)
+		}
+	}
+	return added, updated, removed
+}
+
+// diffProvisionWatchers returns the provision watchers to add to the cache, the
+// patches of the cached provision watchers changed in metadata and the names of the
+// provision watchers to remove from the cache.
+func diffProvisionWatchers(cached, current []models.ProvisionWatcher) ([]models.ProvisionWatcher, []dtos.UpdateProvisionWatcher, []string) {
+	var added []models.ProvisionWatcher
+	var updated []dtos.UpdateProvisionWatcher
+	var removed []string
+
+	cachedByName := make(map[string]models.ProvisionWatcher, len(cached))
+	for _, pw := range cached {
+		cachedByName[pw.Name] = pw
+	}
+	currentNames := make(map[string]bool, len(current))
+	for _, pw := range current {
+		currentNames[pw.Name] = true
+		c, ok := cachedByName[pw.Name]
+		if !ok {
+			added = append(added, pw)
+			continue
+		}
+		patch := dtos.FromProvisionWatcherModelToUpdateDTO(pw)
+		patched := c
+		requests.ReplaceProvisionWatcherModelFieldsWithDTO(&patched, patch)
+		if !reflect.DeepEqual(dtos.FromProvisionWatcherModelToUpdateDTO(patched), dtos.FromProvisionWatcherModelToUpdateDTO(c)) {
+			updated = append(updated, patch)
+		}
+	}
+	for _, pw := range cached {
+		if !currentNames[pw.Name] {
+			removed = append(removed, pw.Name)
+		}
+	}
+	return added, updated, removed
+}
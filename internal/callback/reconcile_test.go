@@ -0,0 +1,156 @@
+package callback
+
+import (
+	"context"
+	"testing"
+
+	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/clients/interfaces"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/responses"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
+)
+
+type unreachableDeviceClient struct {
+	interfaces.DeviceClient
+}
+
+func (unreachableDeviceClient) DevicesByServiceName(context.Context, string, int, int) (responses.MultiDevicesResponse, errors.EdgeX) {
+	return responses.MultiDevicesResponse{}, errors.NewCommonEdgeX(errors.KindServiceUnavailable, "metadata unreachable", nil)
+}
+
+type profileClient struct {
+	interfaces.DeviceProfileClient
+}
+
+type watcherClient struct {
+	interfaces.ProvisionWatcherClient
+	watchers []dtos.ProvisionWatcher
+}
+
+func (c watcherClient) ProvisionWatchersByServiceName(context.Context, string, int, int) (responses.MultiProvisionWatchersResponse, errors.EdgeX) {
+	return responses.MultiProvisionWatchersResponse{ProvisionWatchers: c.watchers}, nil
+}
+
+func testDevice(name string, address string) models.Device {
+	return dtos.ToDeviceModel(dtos.Device{
+		Name:           name,
+		ProfileName:    "profile",
+		ServiceName:    "service",
+		AdminState:     string(models.Unlocked),
+		OperatingState: string(models.Up),
+		Protocols:      map[string]dtos.ProtocolProperties{"tcp": {"Address": address}},
+	})
+}
+
+func TestDiffDevices(t *testing.T) {
+	unchanged := testDevice("unchanged", "10.0.0.1")
+	reported := unchanged
+	reported.LastReported = 1000
+	changed := testDevice("changed", "10.0.0.2")
+	moved := testDevice("changed", "10.0.0.3")
+
+	cached := []models.Device{unchanged, changed, testDevice("removed", "10.0.0.4")}
+	current := []models.Device{reported, moved, testDevice("added", "10.0.0.5")}
+
+	added, updated, removed := diffDevices(cached, current)
+	require.Len(t, added, 1)
+	assert.Equal(t, "added", added[0].Name)
+	require.Len(t, updated, 1)
+	assert.Equal(t, "changed", *updated[0].Name)
+	assert.Equal(t, "10.0.0.3", updated[0].Protocols["tcp"]["Address"])
+	assert.Nil(t, updated[0].LastReported)
+	assert.Equal(t, []string{"removed"}, removed)
+
+	added, updated, removed = diffDevices(current, current)
+	assert.Empty(t, added)
+	assert.Empty(t, updated)
+	assert.Empty(t, removed)
+}
+
+func TestDiffDevicesKeepsOperatingState(t *testing.T) {
+	down := testDevice("device", "10.0.0.1")
+	down.OperatingState = models.Down
+
+	// the device set DOWN locally is not reverted to the UP state of metadata
+	_, updated, _ := diffDevices([]models.Device{down}, []models.Device{testDevice("device", "10.0.0.1")})
+	assert.Empty(t, updated)
+
+	moved := testDevice("device", "10.0.0.2")
+	_, updated, _ = diffDevices([]models.Device{down}, []models.Device{moved})
+	require.Len(t, updated, 1)
+	assert.Nil(t, updated[0].OperatingState)
+}
+
+func TestDiffProvisionWatchers(t *testing.T) {
+	watcher := func(name string, identifier string) models.ProvisionWatcher {
+		return dtos.ToProvisionWatcherModel(dtos.ProvisionWatcher{
+			Name:        name,
+			Identifiers: map[string]string{"Address": identifier},
+			ProfileName: "profile",
+			ServiceName: "service",
+			AdminState:  string(models.Unlocked),
+		})
+	}
+
+	cached := []models.ProvisionWatcher{watcher("unchanged", ".*"), watcher("changed", ".*"), watcher("removed", ".*")}
+	current := []models.ProvisionWatcher{watcher("unchanged", ".*"), watcher("changed", "^10\\."), watcher("added", ".*")}
+
+	added, updated, removed := diffProvisionWatchers(cached, current)
+	require.Len(t, added, 1)
+	assert.Equal(t, "added", added[0].Name)
+	require.Len(t, updated, 1)
+	assert.Equal(t, "changed", *updated[0].Name)
+	assert.Equal(t, []string{"removed"}, removed)
+}
+
+func TestDriftTotal(t *testing.T) {
+	drift := Drift{DevicesAdded: 1, ProfilesUpdated: 2}
+	drift.add(Drift{DevicesAdded: 1, WatchersRemoved: 3})
+
+	assert.Equal(t, uint64(7), drift.Total())
+	assert.Equal(t, uint64(2), drift.counts()["devicesAdded"])
+}
+
+func TestReconcileEachCache(t *testing.T) {
+	lc := logger.NewMockClient()
+	watchers := watcherClient{watchers: []dtos.ProvisionWatcher{
+		{Id: "w1", Name: "watcher", ServiceName: "service", Identifiers: map[string]string{"Address": ".*"}},
+	}}
+	// the caches start empty, metadata being unreachable
+	cache.InitCache("service", lc, profileClient{}, unreachableDeviceClient{}, watcherClient{}, "")
+
+	dic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return lc
+		},
+		container.DeviceServiceName: func(get di.Get) interface{} {
+			return models.DeviceService{Name: "service"}
+		},
+		container.MetadataDeviceClientName: func(get di.Get) interface{} {
+			return unreachableDeviceClient{}
+		},
+		container.MetadataDeviceProfileClientName: func(get di.Get) interface{} {
+			return profileClient{}
+		},
+		container.MetadataProvisionWatcherClientName: func(get di.Get) interface{} {
+			return watchers
+		},
+	})
+
+	// the provision watchers are reconciled although the devices cannot be
+	drift, err := Reconcile(context.Background(), dic)
+	require.Error(t, err)
+	assert.Equal(t, errors.KindServiceUnavailable, errors.Kind(err))
+	assert.EqualValues(t, 1, drift.WatchersAdded)
+	_, ok := cache.ProvisionWatchers().ForName("watcher")
+	assert.True(t, ok)
+}
@@ -151,6 +151,132 @@ func CommandHandler(isRead bool, sendEvent bool, correlationID string, vars map[
 	}
 }
 
+// ReadCommandsHandler reads several GET commands and/or device resources of a device
+// at once, see CommandProcessor.ReadCommands. It is used by AutoEvents to read all
+// resources polled at the same frequency in a single driver call.
+func ReadCommandsHandler(correlationID string, deviceName string, cmds []string, dic *di.Container) (events []dtos.Event, errs []edgexErr.EdgeX, err edgexErr.EdgeX) {
+	var device models.Device
+	defer func() {
+		if err != nil {
+			return
+		}
+		go sdkCommon.UpdateLastConnected(
+			device,
+			container.ConfigurationFrom(dic.Get),
+			bootstrapContainer.LoggingClientFrom(dic.Get),
+			container.MetadataDeviceClientFrom(dic.Get))
+	}()
+
+	device, err = unlockedDevice(deviceName, dic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	helper := NewCommandProcessor(&device, nil, correlationID, "", "", dic)
+	return helper.ReadCommands(cmds)
+}
+
+// ReadTagHandler reads all the readable device resources of a device having the
+// tag given in vars in a single event, see CommandProcessor.ReadTag.
+func ReadTagHandler(sendEvent bool, correlationID string, vars map[string]string, params string, dic *di.Container) (res responses.EventResponse, err edgexErr.EdgeX) {
+	var device models.Device
+	defer func() {
+		if err != nil {
+			return
+		}
+		go sdkCommon.UpdateLastConnected(
+			device,
+			container.ConfigurationFrom(dic.Get),
+			bootstrapContainer.LoggingClientFrom(dic.Get),
+			container.MetadataDeviceClientFrom(dic.Get))
+
+		if sendEvent {
+			ec := container.CoredataEventClientFrom(dic.Get)
+			lc := bootstrapContainer.LoggingClientFrom(dic.Get)
+			go SendEvent(res, correlationID, lc, ec)
+		}
+	}()
+
+	device, err = unlockedDevice(vars[sdkCommon.NameVar], dic)
+	if err != nil {
+		return res, err
+	}
+
+	helper := NewCommandProcessor(&device, nil, correlationID, "", params, dic)
+	event, err := helper.ReadTag(vars[sdkCommon.TagVar])
+	if err != nil {
+		return res, err
+	}
+	return responses.NewEventResponse(correlationID, "", http.StatusOK, event), nil
+}
+
+// unlockedDevice returns the device from the cache, checking that neither the device
+// service nor the device is locked.
+func unlockedDevice(deviceName string, dic *di.Container) (models.Device, edgexErr.EdgeX) {
+	// check device service's AdminState
+	ds := container.DeviceServiceFrom(dic.Get)
+	if ds.AdminState == models.Locked {
+		return models.Device{}, edgexErr.NewCommonEdgeX(edgexErr.KindServiceLocked, "service locked", nil)
+	}
+
+	// check provided device exists
+	device, exist := cache.Devices().ForName(deviceName)
+	if !exist {
+		return models.Device{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, fmt.Sprintf("device %s not found", deviceName), nil)
+	}
+
+	// check device's AdminState
+	if device.AdminState == models.Locked {
+		return models.Device{}, edgexErr.NewCommonEdgeX(edgexErr.KindServiceLocked, fmt.Sprintf("device %s locked", device.Name), nil)
+	}
+	return device, nil
+}
+
+// TaggedResources returns the names of the readable device resources of the profile
+// having the tag, in the order of the profile. Write-only resources are skipped.
+func TaggedResources(profileName string, tag string) []string {
+	var names []string
+	for _, dr := range cache.Profiles().ResourcesByTag(profileName, tag) {
+		if dr.Properties.ReadWrite != sdkCommon.DeviceResourceWriteOnly {
+			names = append(names, dr.Name)
+		}
+	}
+	return names
+}
+
+// ReadTag reads all the readable device resources of the device having the tag,
+// in as few driver calls as MaxCmdOps allows, and returns their readings in a
+// single event.
+func (c *CommandProcessor) ReadTag(tag string) (dtos.Event, edgexErr.EdgeX) {
+	drNames := TaggedResources(c.device.ProfileName, tag)
+	if len(drNames) == 0 {
+		errMsg := fmt.Sprintf("no readable deviceResource with tag %s for %s", tag, c.device.Name)
+		return dtos.Event{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, errMsg, nil)
+	}
+
+	events, errs, err := c.ReadCommands(drNames)
+	if err != nil {
+		return dtos.Event{}, err
+	}
+	// the readings of the resources read successfully are returned, unless all failed
+	lc := bootstrapContainer.LoggingClientFrom(c.dic.Get)
+	event := events[0]
+	event.Readings = nil
+	failures := 0
+	for i, e := range events {
+		if errs[i] != nil {
+			lc.Error(fmt.Sprintf("failed to read deviceResource %s with tag %s for %s: %v", drNames[i], tag, c.device.Name, errs[i]), sdkCommon.CorrelationHeader, c.correlationID)
+			failures++
+			continue
+		}
+		event.Readings = append(event.Readings, e.Readings...)
+	}
+	if failures == len(events) {
+		return dtos.Event{}, errs[0]
+	}
+	return event, nil
+}
+
 func (c *CommandProcessor) ReadDeviceResource() (res responses.EventResponse, e edgexErr.EdgeX) {
 	lc := bootstrapContainer.LoggingClientFrom(c.dic.Get)
 	lc.Debug(fmt.Sprintf("Application - readDeviceResource: reading deviceResource: %s", c.deviceResource.Name), sdkCommon.CorrelationHeader, c.correlationID)
@@ -256,6 +382,149 @@ func (c *CommandProcessor) ReadCommand() (res responses.EventResponse, e edgexEr
 	return
 }
 
+// ReadCommands reads several GET commands and/or device resources of the device
+// in as few driver calls as MaxCmdOps allows. Each device resource is requested
+// only once. It returns one event per entry of cmds, in the same order, and the
+// error of each command which cannot be read, such as an unknown command or a
+// reading failing its transformation; the event of such a command has no readings.
+// The error is only returned if the driver fails to read.
+func (c *CommandProcessor) ReadCommands(cmds []string) ([]dtos.Event, []edgexErr.EdgeX, edgexErr.EdgeX) {
+	lc := bootstrapContainer.LoggingClientFrom(c.dic.Get)
+	lc.Debug(fmt.Sprintf("Application - readCmds: reading cmds: %v", cmds), sdkCommon.CorrelationHeader, c.correlationID)
+
+	// collect the deviceResources of every command, requesting each of them only once
+	var reqs []dsModels.CommandRequest
+	requested := make(map[string]bool)
+	cmdResources := make([]map[string]bool, len(cmds))
+	errs := make([]edgexErr.EdgeX, len(cmds))
+	for i, cmd := range cmds {
+		drNames, err := c.readableResources(cmd)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		cmdResources[i] = make(map[string]bool, len(drNames))
+		for _, drName := range drNames {
+			cmdResources[i][drName] = true
+			if requested[drName] {
+				continue
+			}
+			requested[drName] = true
+
+			dr, _ := cache.Profiles().DeviceResource(c.device.ProfileName, drName)
+			req := dsModels.CommandRequest{
+				DeviceResourceName: dr.Name,
+				Attributes:         dr.Attributes,
+				Type:               dr.Properties.Type,
+			}
+			if c.params != "" {
+				// the attributes of the cached profile are not modified
+				req.Attributes = make(map[string]string, len(dr.Attributes)+1)
+				for k, v := range dr.Attributes {
+					req.Attributes[k] = v
+				}
+				req.Attributes[sdkCommon.URLRawQuery] = c.params
+			}
+			reqs = append(reqs, req)
+		}
+	}
+
+	// execute protocol-specific read operations, at most MaxCmdOps requests at a time
+	configuration := container.ConfigurationFrom(c.dic.Get)
+	batchSize := configuration.Device.MaxCmdOps
+	if batchSize <= 0 {
+		batchSize = len(reqs)
+	}
+	driver := container.ProtocolDriverFrom(c.dic.Get)
+	results := make([]*dsModels.CommandValue, 0, len(reqs))
+	for start := 0; start < len(reqs); start += batchSize {
+		end := start + batchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+		cvs, err := driver.HandleReadCommands(c.device.Name, c.device.Protocols, reqs[start:end])
+		if err != nil {
+			errMsg := fmt.Sprintf("error reading DeviceCommands %v for %s: %v", cmds, c.device.Name, err)
+			return nil, nil, edgexErr.NewCommonEdgeX(edgexErr.KindServerError, errMsg, err)
+		}
+		results = append(results, cvs...)
+	}
+
+	// convert each CommandValue once, a failure only fails the commands reading it
+	readings := make([]dtos.BaseReading, 0, len(results))
+	failed := make(map[string]edgexErr.EdgeX)
+	for _, cv := range results {
+		reading, err := c.commandValueToReading(cv)
+		if err != nil {
+			failed[cv.DeviceResourceName] = err
+			continue
+		}
+		readings = append(readings, reading)
+	}
+	return splitReadings(c.newEvent(nil), readings, cmds, cmdResources, failed, errs), errs, nil
+}
+
+// splitReadings returns one event per command with the readings of its device
+// resources. A command reading a failed device resource gets an event without
+// readings and its error is set in errs.
+func splitReadings(base dtos.Event, readings []dtos.BaseReading, cmds []string, cmdResources []map[string]bool,
+	failed map[string]edgexErr.EdgeX, errs []edgexErr.EdgeX) []dtos.Event {
+	events := make([]dtos.Event, len(cmds))
+	for i, cmd := range cmds {
+		event := base
+		event.Id = uuid.NewString()
+		event.Origin = sdkCommon.GetUniqueOrigin()
+		event.Readings = make([]dtos.BaseReading, 0, len(cmdResources[i]))
+		for drName := range cmdResources[i] {
+			if err, ok := failed[drName]; ok && errs[i] == nil {
+				errMsg := fmt.Sprintf("GET command %s transform failed for %s", cmd, base.DeviceName)
+				errs[i] = edgexErr.NewCommonEdgeX(edgexErr.Kind(err), errMsg, err)
+			}
+		}
+		if errs[i] == nil {
+			for _, r := range readings {
+				if cmdResources[i][r.ResourceName] {
+					event.Readings = append(event.Readings, r)
+				}
+			}
+		}
+		events[i] = event
+	}
+	return events
+}
+
+// readableResources returns the names of the deviceResources read by the given
+// GET command, or the deviceResource itself if cmd names a deviceResource.
+func (c *CommandProcessor) readableResources(cmd string) ([]string, edgexErr.EdgeX) {
+	var drNames []string
+	if exists, _ := cache.Profiles().CommandExists(c.device.ProfileName, cmd, sdkCommon.GetCmdMethod); exists {
+		ros, err := cache.Profiles().ResourceOperations(c.device.ProfileName, cmd, sdkCommon.GetCmdMethod)
+		if err != nil {
+			errMsg := fmt.Sprintf("GET ResourceOperation(s) for %s command not found", cmd)
+			return nil, edgexErr.NewCommonEdgeX(edgexErr.KindNotAllowed, errMsg, err)
+		}
+		for _, ro := range ros {
+			drNames = append(drNames, ro.DeviceResource)
+		}
+	} else {
+		drNames = []string{cmd}
+	}
+
+	for _, drName := range drNames {
+		dr, ok := cache.Profiles().DeviceResource(c.device.ProfileName, drName)
+		if !ok {
+			errMsg := fmt.Sprintf("deviceResource %s in GET command %s for %s not defined", drName, cmd, c.device.Name)
+			return nil, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, errMsg, nil)
+		}
+		if dr.Properties.ReadWrite == sdkCommon.DeviceResourceWriteOnly {
+			errMsg := fmt.Sprintf("deviceResource %s in GET command %s is marked as write-only", drName, cmd)
+			return nil, edgexErr.NewCommonEdgeX(edgexErr.KindNotAllowed, errMsg, nil)
+		}
+	}
+
+	return drNames, nil
+}
+
 func (c *CommandProcessor) WriteDeviceResource() edgexErr.EdgeX {
 	lc := bootstrapContainer.LoggingClientFrom(c.dic.Get)
 	lc.Debug(fmt.Sprintf("Application - writeDeviceResource: writting deviceResource: %s", c.deviceResource.Name), sdkCommon.CorrelationHeader, c.correlationID)
@@ -428,74 +697,93 @@ func (c *CommandProcessor) WriteCommand() edgexErr.EdgeX {
 }
 
 func (c *CommandProcessor) commandValuesToEvent(cvs []*dsModels.CommandValue, cmd string) (dtos.Event, edgexErr.EdgeX) {
-	var err error
-	var transformsOK = true
-	lc := bootstrapContainer.LoggingClientFrom(c.dic.Get)
-
 	configuration := container.ConfigurationFrom(c.dic.Get)
 	readings := make([]dtos.BaseReading, 0, configuration.Device.MaxCmdOps)
-
+	transformsOK := true
 	for _, cv := range cvs {
-		// double check the CommandValue return from ProtocolDriver match device command
-		dr, ok := cache.Profiles().DeviceResource(c.device.ProfileName, cv.DeviceResourceName)
-		if !ok {
-			return dtos.Event{}, edgexErr.NewCommonEdgeXWrapper(fmt.Errorf("no deviceResource %s for %s in CommandValue (%s)", cv.DeviceResourceName, c.device.Name, cv.String()))
-		}
-
-		// perform data transformation
-		if configuration.Device.DataTransform {
-			err = transformer.TransformReadResult(cv, dr.Properties, lc)
-			lc.Debug(fmt.Sprintf("command value: %+v", cv))
-			if err != nil {
-				lc.Error(fmt.Sprintf("failed to transform CommandValue (%s): %v", cv.String(), err), sdkCommon.CorrelationHeader, c.correlationID)
-
-				if errors.As(err, &transformer.OverflowError{}) {
-					cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, transformer.Overflow)
-				} else if errors.As(err, &transformer.NaNError{}) {
-					cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, transformer.NaN)
-				} else {
-					transformsOK = false
-				}
+		reading, err := c.commandValueToReading(cv)
+		if err != nil {
+			if edgexErr.Kind(err) != edgexErr.KindContractInvalid {
+				return dtos.Event{}, err
 			}
+			transformsOK = false
+			continue
 		}
+		readings = append(readings, reading)
+	}
+
+	if !transformsOK {
+		return dtos.Event{}, edgexErr.NewCommonEdgeXWrapper(fmt.Errorf("GET command %s transform failed for %s", cmd, c.device.Name))
+	}
+	return c.newEvent(readings), nil
+}
+
+// commandValueToReading transforms, checks and maps the CommandValue read from the
+// driver and returns its reading. It returns a KindContractInvalid error if the
+// value cannot be transformed.
+func (c *CommandProcessor) commandValueToReading(cv *dsModels.CommandValue) (dtos.BaseReading, edgexErr.EdgeX) {
+	lc := bootstrapContainer.LoggingClientFrom(c.dic.Get)
+	configuration := container.ConfigurationFrom(c.dic.Get)
+
+	// double check the CommandValue return from ProtocolDriver match device command
+	dr, ok := cache.Profiles().DeviceResource(c.device.ProfileName, cv.DeviceResourceName)
+	if !ok {
+		return dtos.BaseReading{}, edgexErr.NewCommonEdgeXWrapper(fmt.Errorf("no deviceResource %s for %s in CommandValue (%s)", cv.DeviceResourceName, c.device.Name, cv.String()))
+	}
 
-		// assertion
-		dc := container.MetadataDeviceClientFrom(c.dic.Get)
-		err = transformer.CheckAssertion(cv, dr.Properties.Assertion, c.device, lc, dc)
+	// perform data transformation
+	if configuration.Device.DataTransform {
+		err := transformer.TransformReadResult(cv, dr.Properties, lc)
+		lc.Debug(fmt.Sprintf("command value: %+v", cv))
 		if err != nil {
-			cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource: %s, with value: %s", cv.DeviceResourceName, cv.String()))
-		}
-		// ResourceOperation mapping
-		ro, exrr := cache.Profiles().ResourceOperation(c.device.ProfileName, cv.DeviceResourceName, sdkCommon.GetCmdMethod)
-		if exrr != nil {
-			// this allows SDK to directly read deviceResource without deviceCommands defined.
-			lc.Debug(fmt.Sprintf("failed to read ResourceOperation: %v", exrr), sdkCommon.CorrelationHeader, c.correlationID)
-		} else if len(ro.Mappings) > 0 {
-			newCV, ok := transformer.MapCommandValue(cv, ro.Mappings)
-			if ok {
-				cv = newCV
+			lc.Error(fmt.Sprintf("failed to transform CommandValue (%s): %v", cv.String(), err), sdkCommon.CorrelationHeader, c.correlationID)
+
+			if errors.As(err, &transformer.OverflowError{}) {
+				cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, transformer.Overflow)
+			} else if errors.As(err, &transformer.NaNError{}) {
+				cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, transformer.NaN)
 			} else {
-				lc.Warn(fmt.Sprintf("ResourceOperation (%s) mapping value (%s) failed with the mapping table: %v", ro.DeviceResource, cv.String(), ro.Mappings), sdkCommon.CorrelationHeader, c.correlationID)
+				errMsg := fmt.Sprintf("failed to transform deviceResource %s for %s", cv.DeviceResourceName, c.device.Name)
+				return dtos.BaseReading{}, edgexErr.NewCommonEdgeX(edgexErr.KindContractInvalid, errMsg, err)
 			}
 		}
+	}
 
-		lc.Debug(fmt.Sprintf("command value: %+v", cv))
-
-		reading := commandValueToReading(cv, c.device.Name, c.device.ProfileName, dr.Properties.MediaType, "")
-		readings = append(readings, reading)
-
-		if cv.Type == contracts.ValueTypeBinary {
-			lc.Debug(fmt.Sprintf("device: %s DeviceResource: %v reading: binary value", c.device.Name, cv.DeviceResourceName), sdkCommon.CorrelationHeader, c.correlationID)
+	// assertion
+	dc := container.MetadataDeviceClientFrom(c.dic.Get)
+	err := transformer.CheckAssertion(cv, dr.Properties.Assertion, c.device, lc, dc)
+	if err != nil {
+		cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource: %s, with value: %s", cv.DeviceResourceName, cv.String()))
+	}
+	// ResourceOperation mapping
+	ro, exrr := cache.Profiles().ResourceOperation(c.device.ProfileName, cv.DeviceResourceName, sdkCommon.GetCmdMethod)
+	if exrr != nil {
+		// this allows SDK to directly read deviceResource without deviceCommands defined.
+		lc.Debug(fmt.Sprintf("failed to read ResourceOperation: %v", exrr), sdkCommon.CorrelationHeader, c.correlationID)
+	} else if len(ro.Mappings) > 0 {
+		newCV, ok := transformer.MapCommandValue(cv, ro.Mappings)
+		if ok {
+			cv = newCV
 		} else {
-			lc.Debug(fmt.Sprintf("device: %s DeviceResource: %v reading: %v", c.device.Name, cv.DeviceResourceName, reading), sdkCommon.CorrelationHeader, c.correlationID)
+			lc.Warn(fmt.Sprintf("ResourceOperation (%s) mapping value (%s) failed with the mapping table: %v", ro.DeviceResource, cv.String(), ro.Mappings), sdkCommon.CorrelationHeader, c.correlationID)
 		}
 	}
 
-	if !transformsOK {
-		return dtos.Event{}, edgexErr.NewCommonEdgeXWrapper(fmt.Errorf("GET command %s transform failed for %s", cmd, c.device.Name))
+	lc.Debug(fmt.Sprintf("command value: %+v", cv))
+
+	reading := commandValueToReading(cv, c.device.Name, c.device.ProfileName, dr.Properties.MediaType, "")
+	if cv.Type == contracts.ValueTypeBinary {
+		lc.Debug(fmt.Sprintf("device: %s DeviceResource: %v reading: binary value", c.device.Name, cv.DeviceResourceName), sdkCommon.CorrelationHeader, c.correlationID)
+	} else {
+		lc.Debug(fmt.Sprintf("device: %s DeviceResource: %v reading: %v", c.device.Name, cv.DeviceResourceName, reading), sdkCommon.CorrelationHeader, c.correlationID)
 	}
+	return reading, nil
+}
 
-	return dtos.Event{
+// newEvent returns an event of the device with the readings
+func (c *CommandProcessor) newEvent(readings []dtos.BaseReading) dtos.Event {
+	configuration := container.ConfigurationFrom(c.dic.Get)
+	event := dtos.Event{
 		Versionable: common.Versionable{
 			ApiVersion: contracts.ApiVersion,
 		},
@@ -505,7 +793,9 @@ func (c *CommandProcessor) commandValuesToEvent(cvs []*dsModels.CommandValue, cm
 		DeviceName:  c.device.Name,
 		ProfileName: c.device.ProfileName,
 		Readings:    readings,
-	}, nil
+	}
+	EnrichEvent(&event, *c.device, configuration.Device.Enrichment)
+	return event
 }
 
 func parseParams(params string) (paramMap map[string]interface{}, err error) {
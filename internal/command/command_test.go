@@ -0,0 +1,48 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	edgexErr "github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+)
+
+func TestSplitReadings(t *testing.T) {
+	base := dtos.NewEvent("profile", "meter")
+	readings := []dtos.BaseReading{
+		{ResourceName: "voltage", SimpleReading: dtos.SimpleReading{Value: "230"}},
+		{ResourceName: "current", SimpleReading: dtos.SimpleReading{Value: "5"}},
+	}
+	cmds := []string{"voltage", "power", "unknown", "current"}
+	cmdResources := []map[string]bool{
+		{"voltage": true},
+		{"voltage": true, "power": true},
+		nil,
+		{"current": true},
+	}
+	transformErr := edgexErr.NewCommonEdgeX(edgexErr.KindContractInvalid, "failed to transform deviceResource power", nil)
+	failed := map[string]edgexErr.EdgeX{"power": transformErr}
+	unknownErr := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "deviceResource unknown not defined", nil)
+	errs := []edgexErr.EdgeX{nil, nil, unknownErr, nil}
+
+	events := splitReadings(base, readings, cmds, cmdResources, failed, errs)
+	require.Len(t, events, len(cmds))
+	for _, event := range events {
+		assert.Equal(t, "meter", event.DeviceName)
+	}
+
+	// the commands not reading the failed resource keep their readings
+	assert.Equal(t, readings[:1], events[0].Readings)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, readings[1:], events[3].Readings)
+	assert.NoError(t, errs[3])
+
+	assert.Empty(t, events[1].Readings)
+	require.Error(t, errs[1])
+	assert.Equal(t, edgexErr.KindContractInvalid, edgexErr.Kind(errs[1]))
+	assert.Empty(t, events[2].Readings)
+	assert.Equal(t, unknownErr, errs[2])
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+// Tags attached by EnrichEvent
+const (
+	TagLabels        = "labels"
+	TagLocation      = "location"
+	TagCloudDeviceId = "cloudDeviceId"
+	TagResourceTag   = "tag"
+	TagUnits         = "units"
+)
+
+// EnrichEvent attaches the Device and Device Resource metadata selected by the
+// enrichment configuration as tags of the event and its readings. Empty values
+// are not attached.
+func EnrichEvent(event *dtos.Event, device models.Device, enrichment common.EnrichmentInfo) {
+	if enrichment.Labels && len(device.Labels) > 0 {
+		setTag(&event.Tags, TagLabels, strings.Join(device.Labels, ","))
+	}
+	if enrichment.Location && device.Location != nil {
+		setTag(&event.Tags, TagLocation, locationString(device.Location))
+	}
+	if enrichment.CloudDeviceId && device.CloudDeviceId != "" {
+		setTag(&event.Tags, TagCloudDeviceId, device.CloudDeviceId)
+	}
+
+	if !enrichment.ResourceTag && !enrichment.Units {
+		return
+	}
+	for i := range event.Readings {
+		r := &event.Readings[i]
+		dr, ok := cache.Profiles().DeviceResource(device.ProfileName, r.ResourceName)
+		if !ok {
+			continue
+		}
+		if enrichment.ResourceTag && dr.Tag != "" {
+			setTag(&r.Tags, TagResourceTag, dr.Tag)
+		}
+		if enrichment.Units && dr.Properties.Units != "" {
+			setTag(&r.Tags, TagUnits, dr.Properties.Units)
+		}
+	}
+}
+
+// setTag sets the tag on a copy of tags, which may be shared with other events
+func setTag(tags *map[string]string, name string, value string) {
+	copied := make(map[string]string, len(*tags)+1)
+	for k, v := range *tags {
+		copied[k] = v
+	}
+	copied[name] = value
+	*tags = copied
+}
+
+func locationString(location interface{}) string {
+	if s, ok := location.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(location)
+	if err != nil {
+		return fmt.Sprintf("%v", location)
+	}
+	return string(b)
+}
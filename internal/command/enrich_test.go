@@ -0,0 +1,44 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+func TestEnrichEvent(t *testing.T) {
+	device := models.Device{
+		Name:          "meter",
+		ProfileName:   "profile",
+		Labels:        []string{"floor-1", "power"},
+		Location:      map[string]interface{}{"building": "A"},
+		CloudDeviceId: "cloud-1",
+	}
+	shared := map[string]string{"origin": "test"}
+
+	tests := []struct {
+		name       string
+		device     models.Device
+		enrichment common.EnrichmentInfo
+		expected   map[string]string
+	}{
+		{"disabled", device, common.EnrichmentInfo{}, shared},
+		{"all", device, common.EnrichmentInfo{Labels: true, Location: true, CloudDeviceId: true},
+			map[string]string{"origin": "test", TagLabels: "floor-1,power", TagLocation: `{"building":"A"}`, TagCloudDeviceId: "cloud-1"}},
+		{"string location", models.Device{Location: "room 42"}, common.EnrichmentInfo{Labels: true, Location: true, CloudDeviceId: true},
+			map[string]string{"origin": "test", TagLocation: "room 42"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := dtos.NewEvent(device.ProfileName, device.Name)
+			event.Tags = shared
+			EnrichEvent(&event, tt.device, tt.enrichment)
+			assert.Equal(t, tt.expected, event.Tags)
+		})
+	}
+	assert.Equal(t, map[string]string{"origin": "test"}, shared, "tags shared with other events must not be modified")
+}
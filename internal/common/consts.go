@@ -42,6 +42,7 @@ const (
 	IdVar        string = "id"
 	NameVar      string = "name"
 	CommandVar   string = "command"
+	TagVar       string = "tag"
 	GetCmdMethod string = "get"
 	SetCmdMethod string = "set"
 
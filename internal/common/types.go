@@ -56,11 +56,94 @@ type ServiceInfo struct {
 	// EnableAsyncReadings to determine whether the Device Service would deal with the asynchronous readings
 	EnableAsyncReadings bool
 	// AsyncBufferSize defines the size of asynchronous channel
+	// and the total size of the queues of the async workers, split evenly between them
 	AsyncBufferSize int
+	// AsyncWorkers defines the number of workers processing the asynchronous readings,
+	// the readings of a device are always processed by the same worker in order.
+	// It defaults to AsyncBufferSize.
+	AsyncWorkers int
+	// AsyncOverflowPolicy defines what happens to the asynchronous readings when
+	// the queue of a worker is full: block (default), drop-oldest or drop-newest.
+	// With block, a device filling the queue of its worker holds up all the devices.
+	AsyncOverflowPolicy string
+	// AsyncBatchSize defines the maximum number of asynchronous events sent to
+	// core-data in a single request, batching is disabled if it is less than 2
+	AsyncBatchSize int
+	// AsyncBatchLinger defines how long an asynchronous event may wait for its
+	// batch to fill up before the batch is sent anyway, such as 100ms
+	AsyncBatchLinger string
+	// CacheReconcileInterval defines how often the device, profile and provision
+	// watcher caches are reconciled with Core Metadata, such as 5m. The caches only
+	// rely on the callbacks if it is empty.
+	CacheReconcileInterval string
+	// CacheSnapshotFile is the file the caches are saved to after every change, and
+	// restored from at startup when Core Metadata is unreachable. The caches are
+	// not saved if it is empty.
+	CacheSnapshotFile string
+	// Auth configures the authentication of the REST requests and the policies
+	// applied to the routes, the routes are open if it has no policy.
+	Auth AuthInfo
 
 	DeviceLibraryId string
 }
 
+// AuthInfo configures the authentication methods of the REST requests and the policy
+// of each kind of route: public (ping and version), read and write (the commands of
+// the devices), callback (the callbacks of metadata), admin (the other reserved routes)
+// and driver (the routes added by the device service with AddRoute).
+type AuthInfo struct {
+	// APIKeys maps the names of the clients to their static API key, sent in the
+	// X-API-Key header.
+	APIKeys map[string]string
+	// HMACKeys maps the key ids of the clients signing their requests to the shared
+	// secrets of the signatures.
+	HMACKeys map[string]string
+	// HMACMaxSkew is the maximum difference between the timestamp of a signed request
+	// and the time it is received, such as 5m which is the default.
+	HMACMaxSkew string
+	// HMACMaxBodySize is the maximum size in bytes of the body of a signed request, 1MiB
+	// by default. A larger request is rejected with 413 before its signature is checked.
+	HMACMaxBodySize int64
+	// JWT configures the verification of the JSON Web Tokens sent as bearer tokens.
+	JWT JWTInfo
+	// Policies maps the kinds of route to their policy, the "default" policy applies
+	// to the kinds without policy. A kind of route is open if neither has a policy.
+	Policies map[string]AuthPolicy
+}
+
+// JWTInfo configures the verification of JSON Web Tokens against local keys.
+type JWTInfo struct {
+	// Keys maps the key ids (kid) to the keys verifying the tokens. A token without
+	// key id is verified by the only key if there is a single one.
+	Keys map[string]JWTKeyInfo
+	// Issuer must match the iss claim of the tokens if it is set.
+	Issuer string
+	// Audience must be one of the aud claim of the tokens if it is set.
+	Audience string
+}
+
+// JWTKeyInfo is a key verifying JSON Web Tokens.
+type JWTKeyInfo struct {
+	// Algorithm is the signing algorithm of the tokens: HS256, RS256 or ES256.
+	Algorithm string
+	// Secret is the shared secret of HS256.
+	Secret string
+	// PublicKeyFile is the PEM file holding the public key, or a certificate, of
+	// RS256 and ES256.
+	PublicKeyFile string
+}
+
+// AuthPolicy defines how the requests to a kind of route are authenticated.
+type AuthPolicy struct {
+	// Methods lists the accepted authentication methods: apikey, hmac and jwt, or the
+	// methods added by the device service. The routes are open if it is empty.
+	Methods []string
+	// Clients restricts the authenticated clients allowed, identified by the name of
+	// their API key, their HMAC key id or the subject of their token. Any authenticated
+	// client is allowed if it is empty.
+	Clients []string
+}
+
 // DeviceInfo is a struct which contains device specific configuration settings.
 type DeviceInfo struct {
 	// DataTransform specifies whether or not the DS perform transformations
@@ -89,10 +172,64 @@ type DeviceInfo struct {
 	// UpdateLastConnected specifies whether to update device's LastConnected
 	// timestamp in metadata.
 	UpdateLastConnected bool
+	// CombineAutoEvents specifies whether the readings of AutoEvents that are
+	// polled together (same device and frequency) are published as a single
+	// event, instead of one event per AutoEvent resource.
+	CombineAutoEvents bool
+	// AutoEventBackoff controls how AutoEvents slow down polling for devices
+	// whose reads keep failing.
+	AutoEventBackoff BackoffInfo
+	// Enrichment selects the Device and Device Resource metadata attached as tags
+	// to the events and readings
+	Enrichment EnrichmentInfo
 
 	Discovery DiscoveryInfo
 }
 
+// EnrichmentInfo selects the metadata attached to the events and readings as tags.
+// Labels, Location and CloudDeviceId of the Device are event tags, Tag and Units
+// of the Device Resource are reading tags.
+type EnrichmentInfo struct {
+	Labels        bool
+	Location      bool
+	CloudDeviceId bool
+	ResourceTag   bool
+	Units         bool
+}
+
+// BackoffInfo is a struct which contains the adaptive polling policy of AutoEvents.
+type BackoffInfo struct {
+	// FailureThreshold is the number of consecutive read failures after which
+	// the device is marked DOWN and polling starts to back off exponentially.
+	// Zero or a negative value disables the back-off.
+	FailureThreshold int
+	// MaxInterval caps the polling interval while backing off.
+	// It represents as a duration string.
+	MaxInterval string
+	// Devices overrides the policy for specific devices, keyed by device name.
+	// Unset fields fall back to the global values.
+	Devices map[string]BackoffInfo
+}
+
+// ForDevice returns the back-off policy applied to the given device.
+func (b BackoffInfo) ForDevice(deviceName string) BackoffInfo {
+	policy := BackoffInfo{
+		FailureThreshold: b.FailureThreshold,
+		MaxInterval:      b.MaxInterval,
+	}
+	override, ok := b.Devices[deviceName]
+	if !ok {
+		return policy
+	}
+	if override.FailureThreshold != 0 {
+		policy.FailureThreshold = override.FailureThreshold
+	}
+	if override.MaxInterval != "" {
+		policy.MaxInterval = override.MaxInterval
+	}
+	return policy
+}
+
 // DiscoveryInfo is a struct which contains configuration of device auto discovery.
 type DiscoveryInfo struct {
 	// Enabled controls whether or not device discovery is enabled.
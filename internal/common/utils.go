@@ -76,7 +76,8 @@ func CommandValueToReading(cv *dsModels.CommandValue, devName, profileName, medi
 }
 
 // models to dtos
-func SendEvent(event dtos.Event, lc logger.LoggingClient, ec interfaces.EventClient) {
+// SendEvent returns the error of the failed post, which has already been logged
+func SendEvent(event dtos.Event, lc logger.LoggingClient, ec interfaces.EventClient) errors.EdgeX {
 	correlation := uuid.New().String()
 	ctx := context.WithValue(context.Background(), CorrelationHeader, correlation)
 	req := requests.AddEventRequest{
@@ -87,10 +88,11 @@ func SendEvent(event dtos.Event, lc logger.LoggingClient, ec interfaces.EventCli
 	responseBody, errPost := ec.Add(ctx, req)
 	if errPost != nil {
 		lc.Error("SendEvent Failed to push event", "device", event.DeviceName, "response", responseBody, "error", errPost)
-	} else {
-		lc.Debug("SendEvent: Pushed event to core data", contracts.ContentType, context2.FromContext(ctx, contracts.ContentType), contracts.CorrelationHeader, correlation)
-		lc.Trace("SendEvent: Pushed this event to core data", contracts.ContentType, context2.FromContext(ctx, contracts.ContentType), contracts.CorrelationHeader, correlation, "event", event)
+		return errPost
 	}
+	lc.Debug("SendEvent: Pushed event to core data", contracts.ContentType, context2.FromContext(ctx, contracts.ContentType), contracts.CorrelationHeader, correlation)
+	lc.Trace("SendEvent: Pushed this event to core data", contracts.ContentType, context2.FromContext(ctx, contracts.ContentType), contracts.CorrelationHeader, correlation, "event", event)
+	return nil
 }
 
 func CompareStrings(a []string, b []string) bool {
@@ -177,3 +179,21 @@ func UpdateLastConnected(device models.Device, configuration *ConfigurationStruc
 		lc.Error("Failed to update last connected value for device: " + device.Name)
 	}
 }
+
+func UpdateOperatingState(device models.Device, state models.OperatingState, lc logger.LoggingClient, dc interfaces.DeviceClient) {
+	operatingState := string(state)
+	req := make([]requests.UpdateDeviceRequest, 0, 1)
+	req = append(req, requests.UpdateDeviceRequest{
+		BaseRequest: common.NewBaseRequest(),
+		Device: dtos.UpdateDevice{
+			Name:           &device.Name,
+			ServiceName:    &device.ServiceName,
+			ProfileName:    &device.ProfileName,
+			OperatingState: &operatingState,
+		},
+	})
+	_, err := dc.Update(context.Background(), req)
+	if err != nil {
+		lc.Error(fmt.Sprintf("Failed to update operating state to %s for device: %s", state, device.Name))
+	}
+}
@@ -0,0 +1,33 @@
+package container
+
+import (
+	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/async"
+)
+
+// AsyncWorkerPoolName contains the name of the async.WorkerPool implementation in the DIC.
+var AsyncWorkerPoolName = di.TypeInstanceToName(async.WorkerPool{})
+
+// AsyncWorkerPoolFrom helper function queries the DIC and returns the async.WorkerPool,
+// or nil if the asynchronous readings are disabled.
+func AsyncWorkerPoolFrom(get di.Get) *async.WorkerPool {
+	casted, ok := get(AsyncWorkerPoolName).(*async.WorkerPool)
+	if ok {
+		return casted
+	}
+	return nil
+}
+
+// AsyncRejectionsName contains the name of the async.Rejections implementation in the DIC.
+var AsyncRejectionsName = di.TypeInstanceToName(async.Rejections{})
+
+// AsyncRejectionsFrom helper function queries the DIC and returns the async.Rejections,
+// or nil if the asynchronous readings are disabled.
+func AsyncRejectionsFrom(get di.Get) *async.Rejections {
+	casted, ok := get(AsyncRejectionsName).(*async.Rejections)
+	if ok {
+		return casted
+	}
+	return nil
+}
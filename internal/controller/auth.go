@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/auth"
+	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// AddAuthenticator adds an authentication method the auth policies can accept, or
+// replaces a built-in one.
+func (c *RestController) AddAuthenticator(method string, authenticator dsModels.Authenticator) {
+	c.authorizer.AddAuthenticator(method, authenticator)
+}
+
+// configureAuth applies the auth configuration of the service, if any
+func (c *RestController) configureAuth() {
+	config, ok := c.dic.Get(container.ConfigurationName).(*sdkCommon.ConfigurationStruct)
+	if !ok {
+		return
+	}
+	if err := c.authorizer.Configure(config.Service.Auth); err != nil {
+		c.LoggingClient.Error(fmt.Sprintf("invalid auth configuration, the routes only accepting the invalid methods are closed: %v", err))
+	}
+}
+
+// authorized tells whether the request to the kind of route is authorized by its auth
+// policy, and responds to the request with the error if it is not.
+func (c *RestController) authorized(kind string, route string, writer http.ResponseWriter, request *http.Request) bool {
+	identity, err := c.authorizer.Authorize(kind, request)
+	if err != nil {
+		c.httpController.sendEdgexError(writer, request, err, route)
+		return false
+	}
+	if identity != "" {
+		c.LoggingClient.Debug(fmt.Sprintf("%s %s authorized for %s", request.Method, request.URL.Path, identity))
+	}
+	return true
+}
+
+// reservedRouteKind returns the kind of route, whose policy applies, of a request to
+// a reserved route
+func reservedRouteKind(route string, method string) string {
+	switch route {
+	case contracts.ApiPingRoute, contracts.ApiVersionRoute:
+		return auth.PublicRoutes
+	case contracts.ApiDeviceNameCommandNameRoute:
+		if method == http.MethodGet {
+			return auth.ReadRoutes
+		}
+		return auth.WriteRoutes
+	case contracts.ApiDeviceNameTagRoute, contracts.ApiAllValueDescriptorRoute,
+		contracts.ApiProfileValueDescriptorsRoute, contracts.ApiResourceValueDescriptorRoute:
+		return auth.ReadRoutes
+	case contracts.ApiDeviceCallbackRoute, contracts.ApiDeviceCallbackNameRoute, contracts.ApiProfileCallbackRoute,
+		contracts.ApiProvisionWatcherRoute, contracts.ApiProvisionWatcherByNameRoute, contracts.ApiServiceCallbackRoute:
+		return auth.CallbackRoutes
+	default:
+		return auth.AdminRoutes
+	}
+}
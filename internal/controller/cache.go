@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/responses"
+	edgexErr "github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autoevent"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+// cacheFilter selects the cached entities by the labels and profileName query parameters.
+// An entity matches if it has all the labels and uses the profile.
+type cacheFilter struct {
+	labels      []string
+	profileName string
+}
+
+func newCacheFilter(request *http.Request) cacheFilter {
+	query := request.URL.Query()
+	filter := cacheFilter{profileName: query.Get(contracts.ProfileName)}
+	for _, label := range strings.Split(query.Get(contracts.Labels), contracts.CommaSeparator) {
+		if label = strings.TrimSpace(label); label != "" {
+			filter.labels = append(filter.labels, label)
+		}
+	}
+	return filter
+}
+
+func (f cacheFilter) matches(labels []string, profileName string) bool {
+	if f.profileName != "" && f.profileName != profileName {
+		return false
+	}
+	for _, expected := range f.labels {
+		found := false
+		for _, label := range labels {
+			found = found || label == expected
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// CachedDevices returns the devices of the cache matching the labels and profileName
+// query parameters, sorted by name
+func (c *HttpController) CachedDevices(writer http.ResponseWriter, request *http.Request) {
+	filter := newCacheFilter(request)
+	devices := make([]dtos.Device, 0)
+	for _, d := range cache.Devices().All() {
+		if filter.matches(d.Labels, d.ProfileName) {
+			devices = append(devices, dtos.FromDeviceModelToDTO(d))
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+
+	response := responses.NewMultiDevicesResponse("", "", http.StatusOK, devices, uint32(len(devices)))
+	c.sendResponse(writer, request, contracts.ApiCacheAllDeviceRoute, response, http.StatusOK)
+}
+
+// CachedDevice returns the device of the cache with the given name
+func (c *HttpController) CachedDevice(writer http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)[sdkCommon.NameVar]
+	device, ok := cache.Devices().ForName(name)
+	if !ok {
+		err := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, fmt.Sprintf("device %s not found in cache", name), nil)
+		c.sendEdgexError(writer, request, err, contracts.ApiCacheDeviceByNameRoute)
+		return
+	}
+
+	response := responses.NewDeviceResponse("", "", http.StatusOK, dtos.FromDeviceModelToDTO(device))
+	c.sendResponse(writer, request, contracts.ApiCacheDeviceByNameRoute, response, http.StatusOK)
+}
+
+// CachedProfiles returns the profiles of the cache matching the labels query
+// parameter, and the profileName query parameter if given, sorted by name
+func (c *HttpController) CachedProfiles(writer http.ResponseWriter, request *http.Request) {
+	filter := newCacheFilter(request)
+	profiles := make([]dtos.DeviceProfile, 0)
+	for _, p := range cache.Profiles().All() {
+		if filter.matches(p.Labels, p.Name) {
+			profiles = append(profiles, dtos.FromDeviceProfileModelToDTO(p))
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	response := responses.NewMultiDeviceProfilesResponse("", "", http.StatusOK, profiles, uint32(len(profiles)))
+	c.sendResponse(writer, request, contracts.ApiCacheAllProfileRoute, response, http.StatusOK)
+}
+
+// CachedProfile returns the profile of the cache with the given name
+func (c *HttpController) CachedProfile(writer http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)[sdkCommon.NameVar]
+	profile, ok := cache.Profiles().ForName(name)
+	if !ok {
+		err := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, fmt.Sprintf("profile %s not found in cache", name), nil)
+		c.sendEdgexError(writer, request, err, contracts.ApiCacheProfileByNameRoute)
+		return
+	}
+
+	response := responses.NewDeviceProfileResponse("", "", http.StatusOK, dtos.FromDeviceProfileModelToDTO(profile))
+	c.sendResponse(writer, request, contracts.ApiCacheProfileByNameRoute, response, http.StatusOK)
+}
+
+// CachedProvisionWatchers returns the provision watchers of the cache matching the
+// labels and profileName query parameters, sorted by name
+func (c *HttpController) CachedProvisionWatchers(writer http.ResponseWriter, request *http.Request) {
+	filter := newCacheFilter(request)
+	pws := make([]dtos.ProvisionWatcher, 0)
+	for _, pw := range cache.ProvisionWatchers().All() {
+		if filter.matches(pw.Labels, pw.ProfileName) {
+			pws = append(pws, dtos.FromProvisionWatcherModelToDTO(pw))
+		}
+	}
+	sort.Slice(pws, func(i, j int) bool { return pws[i].Name < pws[j].Name })
+
+	response := responses.NewMultiProvisionWatchersResponse("", "", http.StatusOK, pws)
+	c.sendResponse(writer, request, contracts.ApiCacheAllProvisionWatcherRoute, response, http.StatusOK)
+}
+
+// CachedProvisionWatcher returns the provision watcher of the cache with the given name
+func (c *HttpController) CachedProvisionWatcher(writer http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)[sdkCommon.NameVar]
+	pw, ok := cache.ProvisionWatchers().ForName(name)
+	if !ok {
+		err := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, fmt.Sprintf("provision watcher %s not found in cache", name), nil)
+		c.sendEdgexError(writer, request, err, contracts.ApiCacheProvisionWatcherByNameRoute)
+		return
+	}
+
+	response := responses.NewProvisionWatcherResponse("", "", http.StatusOK, dtos.FromProvisionWatcherModelToDTO(pw))
+	c.sendResponse(writer, request, contracts.ApiCacheProvisionWatcherByNameRoute, response, http.StatusOK)
+}
+
+// RunningAutoEvents returns the AutoEvents executed for the devices matching the
+// labels and profileName query parameters, sorted by device name
+func (c *HttpController) RunningAutoEvents(writer http.ResponseWriter, request *http.Request) {
+	filter := newCacheFilter(request)
+	devices := make([]dtos.DeviceAutoEvents, 0)
+	for deviceName, autoEvents := range autoevent.GetManager().RunningAutoEvents() {
+		d, ok := cache.Devices().ForName(deviceName)
+		if !ok || !filter.matches(d.Labels, d.ProfileName) {
+			continue
+		}
+		devices = append(devices, dtos.DeviceAutoEvents{
+			DeviceName: deviceName,
+			AutoEvents: dtos.FromAutoEventModelsToDTOs(autoEvents),
+		})
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].DeviceName < devices[j].DeviceName })
+
+	response := responses.NewMultiDeviceAutoEventsResponse("", "", http.StatusOK, devices)
+	c.sendResponse(writer, request, contracts.ApiCacheAllAutoEventRoute, response, http.StatusOK)
+}
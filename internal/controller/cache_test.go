@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		labels      []string
+		profileName string
+		expected    bool
+	}{
+		{"no filter", "", nil, "profile", true},
+		{"label", "?labels=power", []string{"floor-1", "power"}, "profile", true},
+		{"all labels", "?labels=power,%20floor-1", []string{"floor-1", "power"}, "profile", true},
+		{"missing label", "?labels=power,floor-2", []string{"floor-1", "power"}, "profile", false},
+		{"profile", "?profileName=profile", nil, "profile", true},
+		{"other profile", "?profileName=other", nil, "profile", false},
+		{"label and profile", "?labels=power&profileName=other", []string{"power"}, "profile", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/api/v2/cache/device/all"+tt.query, nil)
+			assert.Equal(t, tt.expected, newCacheFilter(request).matches(tt.labels, tt.profileName))
+		})
+	}
+}
@@ -88,7 +88,7 @@ func (c *HttpController) UpdateProfile(writer http.ResponseWriter, request *http
 		return
 	}
 
-	edgexErr = callback.UpdateProfile(profileRequest, c.lc)
+	edgexErr = callback.UpdateProfile(profileRequest, c.dic)
 	if edgexErr == nil {
 		res := commonDTO.NewBaseResponse(profileRequest.RequestId, "", http.StatusOK)
 		c.sendResponse(writer, request, contracts.ApiProfileCallbackRoute, res, http.StatusOK)
@@ -151,3 +151,24 @@ func (c *HttpController) UpdateProvisionWatcher(writer http.ResponseWriter, requ
 		c.sendEdgexError(writer, request, edgexErr, contracts.ApiWatcherCallbackRoute)
 	}
 }
+
+func (c *HttpController) UpdateDeviceService(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+
+	var updateDeviceServiceRequest requests.UpdateDeviceServiceRequest
+
+	err := json.NewDecoder(request.Body).Decode(&updateDeviceServiceRequest)
+	if err != nil {
+		edgexErr := errors.NewCommonEdgeX(errors.KindServerError, "failed to decode JSON", err)
+		c.sendEdgexError(writer, request, edgexErr, contracts.ApiServiceCallbackRoute)
+		return
+	}
+
+	edgexErr := callback.UpdateDeviceService(updateDeviceServiceRequest, c.dic)
+	if edgexErr == nil {
+		res := commonDTO.NewBaseResponse(updateDeviceServiceRequest.RequestId, "", http.StatusOK)
+		c.sendResponse(writer, request, contracts.ApiServiceCallbackRoute, res, http.StatusOK)
+	} else {
+		c.sendEdgexError(writer, request, edgexErr, contracts.ApiServiceCallbackRoute)
+	}
+}
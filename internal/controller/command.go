@@ -64,6 +64,35 @@ func (c *HttpController) Command(writer http.ResponseWriter, request *http.Reque
 	}
 }
 
+// ReadTag reads all the device resources of a device having the tag in a single event
+func (c *HttpController) ReadTag(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+
+	vars := mux.Vars(request)
+	correlationID := request.Header.Get(common.CorrelationHeader)
+	params, reserved, err := filterQueryParams(request.URL.RawQuery)
+	if err != nil {
+		c.sendEdgexError(writer, request, err, contracts.ApiDeviceNameTagRoute)
+		return
+	}
+
+	// push event to CoreData if specified (default no)
+	sendEvent := false
+	if ok, exist := reserved[SDKPostEventReserved]; exist && ok[0] == QueryParameterValueYes {
+		sendEvent = true
+	}
+	event, err := command.ReadTagHandler(sendEvent, correlationID, vars, params, c.dic)
+	if err != nil {
+		c.sendEdgexError(writer, request, err, contracts.ApiDeviceNameTagRoute)
+		return
+	}
+
+	// return event in http response if specified (default yes)
+	if ok, exist := reserved[SDKReturnEventReserved]; !exist || ok[0] == QueryParameterValueYes {
+		c.sendResponse(writer, request, contracts.ApiDeviceNameTagRoute, event, http.StatusOK)
+	}
+}
+
 func readBodyAsString(req *http.Request) (string, edgexErr.EdgeX) {
 	defer req.Body.Close()
 	body, err := ioutil.ReadAll(req.Body)
@@ -16,6 +16,7 @@ import (
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/callback"
 	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/telemetry"
@@ -55,6 +56,15 @@ func (c *HttpController) Metrics(writer http.ResponseWriter, request *http.Reque
 		MemTotalAlloc:  telem.Memory.TotalAlloc,
 		CpuBusyAvg:     uint8(telem.CpuBusyAvg),
 	}
+	if pool := container.AsyncWorkerPoolFrom(c.dic.Get); pool != nil {
+		dropped := pool.Dropped()
+		metrics.AsyncDroppedOldest = dropped.Oldest
+		metrics.AsyncDroppedNewest = dropped.Newest
+	}
+	if rejections := container.AsyncRejectionsFrom(c.dic.Get); rejections != nil {
+		metrics.AsyncRejected = rejections.Counts()
+	}
+	metrics.CacheDrift = callback.DriftCounts()
 
 	response := common.NewMetricsResponse(metrics)
 	c.sendResponse(writer, request, contracts.ApiMetricsRoute, response, http.StatusOK)
@@ -7,13 +7,26 @@
 package controller
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 
+	"github.com/gorilla/mux"
+
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/responses"
 	edgexErr "github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autodiscovery"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/provision"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
 
 func (c *HttpController) Discovery(writer http.ResponseWriter, request *http.Request) {
@@ -38,6 +51,110 @@ func (c *HttpController) Discovery(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
-	go autodiscovery.DiscoveryWrapper(discovery, c.lc)
-	c.sendResponse(writer, request, contracts.ApiDiscoveryRoute, nil, http.StatusAccepted)
+	// the request body carrying the options is optional
+	var discoveryRequest requests.DiscoveryRequest
+	if request.Body != nil && request.ContentLength != 0 {
+		defer request.Body.Close()
+		if err := json.NewDecoder(request.Body).Decode(&discoveryRequest); err != nil && err != io.EOF {
+			edgexError := edgexErr.NewCommonEdgeX(edgexErr.KindContractInvalid, "failed to decode JSON", err)
+			c.sendEdgexError(writer, request, edgexError, contracts.ApiDiscoveryRoute)
+			return
+		}
+	}
+
+	job, err := autodiscovery.StartJob(discovery, discoveryRequest.Options, c.lc)
+	if err != nil {
+		c.sendEdgexError(writer, request, err, contracts.ApiDiscoveryRoute)
+		return
+	}
+	response := common.NewBaseWithIdResponse(discoveryRequest.RequestId, "", http.StatusAccepted, job.Id)
+	c.sendResponse(writer, request, contracts.ApiDiscoveryRoute, response, http.StatusAccepted)
+}
+
+// AllDiscoveryJobs returns the running and the recently finished discovery jobs
+func (c *HttpController) AllDiscoveryJobs(writer http.ResponseWriter, request *http.Request) {
+	jobs := autodiscovery.AllJobs()
+	dtoJobs := make([]dtos.DiscoveryJob, len(jobs))
+	for i, job := range jobs {
+		dtoJobs[i] = fromDiscoveryJobToDTO(job)
+	}
+	response := responses.NewMultiDiscoveryJobsResponse("", "", http.StatusOK, dtoJobs)
+	c.sendResponse(writer, request, contracts.ApiAllDiscoveryJobRoute, response, http.StatusOK)
+}
+
+// DiscoveryJob returns the status and the results of a discovery job
+func (c *HttpController) DiscoveryJob(writer http.ResponseWriter, request *http.Request) {
+	id := mux.Vars(request)[sdkCommon.IdVar]
+	job, ok := autodiscovery.JobById(id)
+	if !ok {
+		err := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, fmt.Sprintf("discovery job %s not found", id), nil)
+		c.sendEdgexError(writer, request, err, contracts.ApiDiscoveryJobByIdRoute)
+		return
+	}
+	response := responses.NewDiscoveryJobResponse("", "", http.StatusOK, fromDiscoveryJobToDTO(job))
+	c.sendResponse(writer, request, contracts.ApiDiscoveryJobByIdRoute, response, http.StatusOK)
+}
+
+// CancelDiscoveryJob cancels a running discovery job
+func (c *HttpController) CancelDiscoveryJob(writer http.ResponseWriter, request *http.Request) {
+	id := mux.Vars(request)[sdkCommon.IdVar]
+	if err := autodiscovery.CancelJob(id); err != nil {
+		c.sendEdgexError(writer, request, err, contracts.ApiDiscoveryJobByIdRoute)
+		return
+	}
+	response := common.NewBaseResponse("", "", http.StatusOK)
+	c.sendResponse(writer, request, contracts.ApiDiscoveryJobByIdRoute, response, http.StatusOK)
+}
+
+func fromDiscoveryJobToDTO(job autodiscovery.Job) dtos.DiscoveryJob {
+	return dtos.DiscoveryJob{
+		Id:       job.Id,
+		Status:   string(job.Status),
+		Options:  job.Options,
+		Started:  job.Started,
+		Finished: job.Finished,
+		Error:    job.Error,
+		Found:    job.Found,
+		Added:    job.Added,
+		Updated:  job.Updated,
+		Rejected: job.Rejected,
+	}
+}
+
+// ExplainDiscovery evaluates the DiscoveredDevice in the request body against all the
+// ProvisionWatchers of the device service and explains why each one accepts it or not
+func (c *HttpController) ExplainDiscovery(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+
+	var discovered dtos.DiscoveredDevice
+	if err := json.NewDecoder(request.Body).Decode(&discovered); err != nil {
+		edgexError := edgexErr.NewCommonEdgeX(edgexErr.KindContractInvalid, "failed to decode JSON", err)
+		c.sendEdgexError(writer, request, edgexError, contracts.ApiDiscoveryExplainRoute)
+		return
+	}
+
+	d := dsModels.DiscoveredDevice{
+		Name:        discovered.Name,
+		Protocols:   make(map[string]models.ProtocolProperties, len(discovered.Protocols)),
+		Description: discovered.Description,
+		Labels:      discovered.Labels,
+	}
+	for name, properties := range discovered.Protocols {
+		d.Protocols[name] = models.ProtocolProperties(properties)
+	}
+
+	accepted := false
+	decisions := provision.Explain(d, cache.ProvisionWatchers().All())
+	dtoDecisions := make([]dtos.WatcherDecision, len(decisions))
+	for i, decision := range decisions {
+		accepted = accepted || decision.Accepted
+		dtoDecisions[i] = dtos.WatcherDecision{
+			ProvisionWatcherName: decision.Watcher,
+			Accepted:             decision.Accepted,
+			Reasons:              decision.Reasons,
+		}
+	}
+
+	response := responses.NewExplainDiscoveryResponse("", "", http.StatusOK, accepted, dtoDecisions)
+	c.sendResponse(writer, request, contracts.ApiDiscoveryExplainRoute, response, http.StatusOK)
 }
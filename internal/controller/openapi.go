@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/openapi"
+)
+
+// driverTag is the tag of the routes added by the device service through AddRoute
+const driverTag = "driver"
+
+// routeSummaries holds the summaries of the reserved routes by method and route
+var routeSummaries = map[string]string{
+	http.MethodGet + contracts.ApiPingRoute:                        "Test the service is running",
+	http.MethodGet + contracts.ApiVersionRoute:                     "Get the versions of the service and the SDK",
+	http.MethodGet + contracts.ApiConfigRoute:                      "Get the configuration of the service",
+	http.MethodGet + contracts.ApiMetricsRoute:                     "Get the CPU and memory usage of the service",
+	http.MethodGet + contracts.ApiOpenAPIRoute:                     "Get this OpenAPI document",
+	http.MethodPost + sdkCommon.APIV2SecretRoute:                   "Store a secret of the service",
+	http.MethodPost + contracts.ApiDiscoveryRoute:                  "Start a discovery job",
+	http.MethodPost + contracts.ApiDiscoveryExplainRoute:           "Explain how discovered devices match the provision watchers",
+	http.MethodGet + contracts.ApiAllDiscoveryJobRoute:             "Get the discovery jobs",
+	http.MethodGet + contracts.ApiDiscoveryJobByIdRoute:            "Get a discovery job",
+	http.MethodDelete + contracts.ApiDiscoveryJobByIdRoute:         "Cancel a discovery job",
+	http.MethodGet + contracts.ApiCacheAllDeviceRoute:              "Get the cached devices",
+	http.MethodGet + contracts.ApiCacheDeviceByNameRoute:           "Get a cached device",
+	http.MethodGet + contracts.ApiCacheAllProfileRoute:             "Get the cached profiles",
+	http.MethodGet + contracts.ApiCacheProfileByNameRoute:          "Get a cached profile",
+	http.MethodGet + contracts.ApiCacheAllProvisionWatcherRoute:    "Get the cached provision watchers",
+	http.MethodGet + contracts.ApiCacheProvisionWatcherByNameRoute: "Get a cached provision watcher",
+	http.MethodGet + contracts.ApiCacheAllAutoEventRoute:           "Get the running AutoEvents",
+	http.MethodGet + contracts.ApiAllValueDescriptorRoute:          "Get the value descriptors of the cached profiles",
+	http.MethodGet + contracts.ApiProfileValueDescriptorsRoute:     "Get the value descriptors of a cached profile",
+	http.MethodGet + contracts.ApiResourceValueDescriptorRoute:     "Get the value descriptor of a device resource",
+	http.MethodGet + contracts.ApiDeviceNameCommandNameRoute:       "Read a command of a device",
+	http.MethodPut + contracts.ApiDeviceNameCommandNameRoute:       "Set a command of a device",
+	http.MethodGet + contracts.ApiDeviceNameTagRoute:               "Read the device resources of a device having a tag",
+	http.MethodPost + contracts.ApiDeviceCallbackRoute:             "Notify the service of an added device",
+	http.MethodPut + contracts.ApiDeviceCallbackRoute:              "Notify the service of an updated device",
+	http.MethodDelete + contracts.ApiDeviceCallbackNameRoute:       "Notify the service of a deleted device",
+	http.MethodPut + contracts.ApiProfileCallbackRoute:             "Notify the service of an updated profile",
+	http.MethodPost + contracts.ApiProvisionWatcherRoute:           "Notify the service of an added provision watcher",
+	http.MethodPut + contracts.ApiProvisionWatcherRoute:            "Notify the service of an updated provision watcher",
+	http.MethodDelete + contracts.ApiProvisionWatcherByNameRoute:   "Notify the service of a deleted provision watcher",
+	http.MethodPut + contracts.ApiServiceCallbackRoute:             "Notify the service of its updated device service",
+}
+
+// anyMethods are the methods documented for a route not restricted to some methods
+var anyMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+
+// OpenAPI serves the OpenAPI document of the routes registered on the router
+func (c *RestController) OpenAPI(writer http.ResponseWriter, request *http.Request) {
+	document, err := c.OpenAPIDocument()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.httpController.sendResponse(writer, request, contracts.ApiOpenAPIRoute, document, http.StatusOK)
+}
+
+// OpenAPIDocument generates an OpenAPI document describing the routes registered on the
+// router, the reserved ones and the ones added by the device service, and the commands
+// of the cached profiles.
+func (c *RestController) OpenAPIDocument() (*openapi.Document, error) {
+	ds := container.DeviceServiceFrom(c.dic.Get)
+	document := openapi.New(ds.Name, sdkCommon.ServiceVersion,
+		fmt.Sprintf("REST API of the device service %s, built with the SDK %s", ds.Name, sdkCommon.SDKVersion))
+
+	reservedParams := []openapi.Parameter{
+		yesNoParameter(SDKPostEventReserved, "Push the event to core data, defaults to no"),
+		yesNoParameter(SDKReturnEventReserved, "Return the event in the response, defaults to yes"),
+	}
+	document.AddProfileCommands(contracts.ApiDeviceNameCommandNameRoute, cache.Profiles().All(), reservedParams...)
+
+	err := c.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = anyMethods
+		}
+
+		tag := driverTag
+		if c.reservedRoutes[path] {
+			tag = strings.SplitN(strings.TrimPrefix(path, contracts.ApiBase+"/"), "/", 2)[0]
+		}
+		for _, method := range methods {
+			document.AddRoute(path, method, routeSummaries[method+path], tag)
+		}
+		return nil
+	})
+	return document, err
+}
+
+func yesNoParameter(name string, description string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &openapi.Schema{Type: "string", Enum: []interface{}{QueryParameterValueYes, QueryParameterValueNo}},
+	}
+}
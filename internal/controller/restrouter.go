@@ -19,6 +19,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/auth"
 	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/controller/correlation"
 	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
@@ -29,6 +30,7 @@ type RestController struct {
 	router         *mux.Router
 	reservedRoutes map[string]bool
 	httpController *HttpController
+	authorizer     *auth.Authorizer
 	dic            *di.Container
 }
 
@@ -39,23 +41,43 @@ func NewRestController(r *mux.Router, dic *di.Container) *RestController {
 		router:         r,
 		reservedRoutes: make(map[string]bool),
 		httpController: NewHttpController(dic),
+		authorizer:     auth.NewAuthorizer(),
 		dic:            dic,
 	}
 }
 
 func (c *RestController) InitRestRoutes() {
 	c.LoggingClient.Info("Registering v2 routes...")
+	c.configureAuth()
 
 	c.addReservedRoute(contracts.ApiPingRoute, c.httpController.Ping).Methods(http.MethodGet)
 	c.addReservedRoute(contracts.ApiVersionRoute, c.httpController.Version).Methods(http.MethodGet)
 	c.addReservedRoute(contracts.ApiConfigRoute, c.httpController.Config).Methods(http.MethodGet)
 	c.addReservedRoute(contracts.ApiMetricsRoute, c.httpController.Metrics).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiOpenAPIRoute, c.OpenAPI).Methods(http.MethodGet)
 
 	c.addReservedRoute(sdkCommon.APIV2SecretRoute, c.httpController.Secret).Methods(http.MethodPost)
 
 	c.addReservedRoute(contracts.ApiDiscoveryRoute, c.httpController.Discovery).Methods(http.MethodPost)
+	c.addReservedRoute(contracts.ApiDiscoveryExplainRoute, c.httpController.ExplainDiscovery).Methods(http.MethodPost)
+	c.addReservedRoute(contracts.ApiAllDiscoveryJobRoute, c.httpController.AllDiscoveryJobs).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiDiscoveryJobByIdRoute, c.httpController.DiscoveryJob).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiDiscoveryJobByIdRoute, c.httpController.CancelDiscoveryJob).Methods(http.MethodDelete)
+
+	c.addReservedRoute(contracts.ApiCacheAllDeviceRoute, c.httpController.CachedDevices).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiCacheDeviceByNameRoute, c.httpController.CachedDevice).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiCacheAllProfileRoute, c.httpController.CachedProfiles).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiCacheProfileByNameRoute, c.httpController.CachedProfile).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiCacheAllProvisionWatcherRoute, c.httpController.CachedProvisionWatchers).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiCacheProvisionWatcherByNameRoute, c.httpController.CachedProvisionWatcher).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiCacheAllAutoEventRoute, c.httpController.RunningAutoEvents).Methods(http.MethodGet)
+
+	c.addReservedRoute(contracts.ApiAllValueDescriptorRoute, c.httpController.ValueDescriptors).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiProfileValueDescriptorsRoute, c.httpController.ProfileValueDescriptors).Methods(http.MethodGet)
+	c.addReservedRoute(contracts.ApiResourceValueDescriptorRoute, c.httpController.ResourceValueDescriptor).Methods(http.MethodGet)
 
 	c.addReservedRoute(contracts.ApiDeviceNameCommandNameRoute, c.httpController.Command).Methods(http.MethodPut, http.MethodGet)
+	c.addReservedRoute(contracts.ApiDeviceNameTagRoute, c.httpController.ReadTag).Methods(http.MethodGet)
 
 	c.addReservedRoute(contracts.ApiDeviceCallbackRoute, c.httpController.AddDevice).Methods(http.MethodPost)
 	c.addReservedRoute(contracts.ApiDeviceCallbackRoute, c.httpController.UpdateDevice).Methods(http.MethodPut)
@@ -64,6 +86,7 @@ func (c *RestController) InitRestRoutes() {
 	c.addReservedRoute(contracts.ApiProvisionWatcherRoute, c.httpController.AddProvisionWatcher).Methods(http.MethodPost)
 	c.addReservedRoute(contracts.ApiProvisionWatcherRoute, c.httpController.UpdateProvisionWatcher).Methods(http.MethodPut)
 	c.addReservedRoute(contracts.ApiProvisionWatcherByNameRoute, c.httpController.DeleteProvisionWatcher).Methods(http.MethodDelete)
+	c.addReservedRoute(contracts.ApiServiceCallbackRoute, c.httpController.UpdateDeviceService).Methods(http.MethodPut)
 
 	c.router.Use(correlation.ManageHeader)
 	c.router.Use(correlation.OnResponseComplete)
@@ -75,6 +98,9 @@ func (c *RestController) addReservedRoute(route string, handler func(http.Respon
 	return c.router.HandleFunc(
 		route,
 		func(w http.ResponseWriter, r *http.Request) {
+			if !c.authorized(reservedRouteKind(route, r.Method), route, w, r) {
+				return
+			}
 			ctx := context.WithValue(r.Context(), bootstrapContainer.LoggingClientInterfaceName, c.LoggingClient)
 			handler(
 				w,
@@ -90,6 +116,9 @@ func (c *RestController) AddRoute(route string, handler func(http.ResponseWriter
 	c.router.HandleFunc(
 		route,
 		func(w http.ResponseWriter, r *http.Request) {
+			if !c.authorized(auth.DriverRoutes, route, w, r) {
+				return
+			}
 			ctx := context.WithValue(r.Context(), bootstrapContainer.LoggingClientInterfaceName, c.LoggingClient)
 			handler(
 				w,
@@ -20,14 +20,19 @@ package controller
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/auth"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
 	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
 )
 
@@ -114,3 +119,76 @@ func TestInitRestRoutes(t *testing.T) {
 
 	assert.NoError(t, err, "Unexpected error examining route")
 }
+
+func TestAuthPolicies(t *testing.T) {
+	lc := logger.NewMockClient()
+	config := &common.ConfigurationStruct{}
+	config.Service.Auth = common.AuthInfo{
+		APIKeys: map[string]string{"northbound": "key-1"},
+		Policies: map[string]common.AuthPolicy{
+			auth.WriteRoutes:   {Methods: []string{auth.APIKeyMethod}},
+			auth.DriverRoutes:  {Methods: []string{auth.APIKeyMethod}},
+			auth.DefaultPolicy: {Methods: []string{auth.APIKeyMethod}},
+		},
+	}
+	dic := di.NewContainer(di.ServiceConstructorMap{
+		bootstrapContainer.LoggingClientInterfaceName: func(get di.Get) interface{} {
+			return lc
+		},
+		container.ConfigurationName: func(get di.Get) interface{} {
+			return config
+		},
+	})
+	controller := NewRestController(mux.NewRouter(), dic)
+	controller.InitRestRoutes()
+	err := controller.AddRoute("/api/v2/driver", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, http.MethodGet)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		status int
+	}{
+		{"driver route without key", http.MethodGet, "/api/v2/driver", "", http.StatusUnauthorized},
+		{"driver route with unknown key", http.MethodGet, "/api/v2/driver", "key-2", http.StatusUnauthorized},
+		{"driver route with key", http.MethodGet, "/api/v2/driver", "key-1", http.StatusNoContent},
+		{"write command without key", http.MethodPut, "/api/v2/device/name/meter/switch", "", http.StatusUnauthorized},
+		{"ping without key under the default policy", http.MethodGet, contracts.ApiPingRoute, "", http.StatusOK},
+		{"callback without key under the default policy", http.MethodPut, contracts.ApiDeviceCallbackRoute, "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				request.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			recorder := httptest.NewRecorder()
+			controller.Router().ServeHTTP(recorder, request)
+			assert.Equal(t, tt.status, recorder.Code)
+		})
+	}
+}
+
+func TestReservedRouteKind(t *testing.T) {
+	tests := []struct {
+		route  string
+		method string
+		kind   string
+	}{
+		{contracts.ApiPingRoute, http.MethodGet, auth.PublicRoutes},
+		{contracts.ApiDeviceNameCommandNameRoute, http.MethodGet, auth.ReadRoutes},
+		{contracts.ApiDeviceNameCommandNameRoute, http.MethodPut, auth.WriteRoutes},
+		{contracts.ApiDeviceNameTagRoute, http.MethodGet, auth.ReadRoutes},
+		{contracts.ApiDeviceCallbackRoute, http.MethodPost, auth.CallbackRoutes},
+		{contracts.ApiServiceCallbackRoute, http.MethodPut, auth.CallbackRoutes},
+		{common.APIV2SecretRoute, http.MethodPost, auth.AdminRoutes},
+		{contracts.ApiDiscoveryRoute, http.MethodPost, auth.AdminRoutes},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.kind, reservedRouteKind(tt.route, tt.method), tt.method+" "+tt.route)
+	}
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/responses"
+	edgexErr "github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	sdkCommon "github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+// ValueDescriptors returns the value descriptors of the cached profiles matching the
+// labels and profileName query parameters, sorted by profile name and in resource order
+func (c *HttpController) ValueDescriptors(writer http.ResponseWriter, request *http.Request) {
+	filter := newCacheFilter(request)
+	all := cache.ValueDescriptors().All()
+	profileNames := make([]string, 0, len(all))
+	for name := range all {
+		profileNames = append(profileNames, name)
+	}
+	sort.Strings(profileNames)
+
+	vds := make([]dtos.ValueDescriptor, 0)
+	for _, name := range profileNames {
+		for _, vd := range all[name] {
+			if filter.matches(vd.Labels, name) {
+				vds = append(vds, dtos.FromValueDescriptorModelToDTO(name, vd))
+			}
+		}
+	}
+
+	response := responses.NewMultiValueDescriptorsResponse("", "", http.StatusOK, vds)
+	c.sendResponse(writer, request, contracts.ApiAllValueDescriptorRoute, response, http.StatusOK)
+}
+
+// ProfileValueDescriptors returns the value descriptors of the cached profile with the given name
+func (c *HttpController) ProfileValueDescriptors(writer http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)[sdkCommon.NameVar]
+	vds, ok := cache.ValueDescriptors().ForProfile(name)
+	if !ok {
+		err := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, fmt.Sprintf("profile %s not found in cache", name), nil)
+		c.sendEdgexError(writer, request, err, contracts.ApiProfileValueDescriptorsRoute)
+		return
+	}
+
+	response := responses.NewMultiValueDescriptorsResponse("", "", http.StatusOK, dtos.FromValueDescriptorModelsToDTOs(name, vds))
+	c.sendResponse(writer, request, contracts.ApiProfileValueDescriptorsRoute, response, http.StatusOK)
+}
+
+// ResourceValueDescriptor returns the value descriptor of the device resource of the cached profile
+func (c *HttpController) ResourceValueDescriptor(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	name, resourceName := vars[sdkCommon.NameVar], vars[contracts.ResourceName]
+	vd, ok := cache.ValueDescriptors().ForName(name, resourceName)
+	if !ok {
+		err := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, fmt.Sprintf("device resource %s of profile %s not found in cache", resourceName, name), nil)
+		c.sendEdgexError(writer, request, err, contracts.ApiResourceValueDescriptorRoute)
+		return
+	}
+
+	response := responses.NewValueDescriptorResponse("", "", http.StatusOK, dtos.FromValueDescriptorModelToDTO(name, vd))
+	c.sendResponse(writer, request, contracts.ApiResourceValueDescriptorRoute, response, http.StatusOK)
+}
@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+// profileCommand is a command of a profile, read or set through the command route
+type profileCommand struct {
+	profileName string
+	schema      string
+}
+
+// AddProfileCommands documents the commands of the profiles as paths of the command
+// route, a gorilla/mux path template having the device name and the command variables.
+// A command is either a device command or a device resource, the device command taking
+// precedence when both exist as the command handler does. The event returned by a read
+// and the body of a set are described per profile, and a command of several profiles
+// is documented once with a schema per profile.
+func (d *Document) AddProfileCommands(commandRoute string, profiles []models.DeviceProfile, readParams ...Parameter) {
+	profiles = append([]models.DeviceProfile(nil), profiles...)
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	reads := make(map[string][]profileCommand)
+	sets := make(map[string][]profileCommand)
+	for _, profile := range profiles {
+		for cmd, resources := range commandResources(profile, common.GetCmdMethod) {
+			name := schemaName(profile.Name, cmd, "Event")
+			d.Components.Schemas[name] = d.commandEvent(profile.Name, resources)
+			reads[cmd] = append(reads[cmd], profileCommand{profileName: profile.Name, schema: name})
+		}
+		for cmd, resources := range commandResources(profile, common.SetCmdMethod) {
+			name := schemaName(profile.Name, cmd, "Request")
+			d.Components.Schemas[name] = commandRequest(resources)
+			sets[cmd] = append(sets[cmd], profileCommand{profileName: profile.Name, schema: name})
+		}
+	}
+
+	route := pathVariable.ReplaceAllString(commandRoute, "{$1}")
+	commandVar := "{" + common.CommandVar + "}"
+	for cmd, pcs := range reads {
+		path := strings.Replace(route, commandVar, cmd, 1)
+		d.setOperation(path, http.MethodGet, &Operation{
+			Summary:     fmt.Sprintf("Read %s from a device", cmd),
+			Description: "Available to the devices using " + profileNames(pcs),
+			Tags:        []string{"command"},
+			Parameters:  append(pathParameters(path), readParams...),
+			Responses: map[string]*Response{
+				"200": {
+					Description: "The event holding the readings",
+					Content: jsonContent(&Schema{AllOf: []*Schema{
+						ref(baseResponseSchema),
+						{Type: "object", Properties: map[string]*Schema{"event": oneOf(pcs)}},
+					}}),
+				},
+				"default": errorResponse(),
+			},
+		})
+	}
+	for cmd, pcs := range sets {
+		path := strings.Replace(route, commandVar, cmd, 1)
+		d.setOperation(path, http.MethodPut, &Operation{
+			Summary:     fmt.Sprintf("Set %s of a device", cmd),
+			Description: "Available to the devices using " + profileNames(pcs),
+			Tags:        []string{"command"},
+			Parameters:  pathParameters(path),
+			RequestBody: &RequestBody{
+				Description: "The values to set by device resource name",
+				Required:    true,
+				Content:     jsonContent(oneOf(pcs)),
+			},
+			Responses: map[string]*Response{
+				"200":     {Description: "OK", Content: jsonContent(ref(baseResponseSchema))},
+				"default": errorResponse(),
+			},
+		})
+	}
+}
+
+// commandResources returns the device resources read or set by each command of the
+// profile supporting the method
+func commandResources(profile models.DeviceProfile, method string) map[string][]models.DeviceResource {
+	resources := make(map[string]models.DeviceResource, len(profile.DeviceResources))
+	commands := make(map[string][]models.DeviceResource)
+	for _, dr := range profile.DeviceResources {
+		resources[dr.Name] = dr
+		if dr.Properties.ReadWrite != forbiddenAccess(method) {
+			commands[dr.Name] = []models.DeviceResource{dr}
+		}
+	}
+	for _, pr := range profile.DeviceCommands {
+		ros := pr.Get
+		if method == common.SetCmdMethod {
+			ros = pr.Set
+		}
+		if len(ros) == 0 {
+			continue
+		}
+		commands[pr.Name] = nil
+		for _, ro := range ros {
+			if dr, ok := resources[ro.DeviceResource]; ok {
+				commands[pr.Name] = append(commands[pr.Name], dr)
+			}
+		}
+	}
+	return commands
+}
+
+// forbiddenAccess returns the readWrite of the device resources not supporting the method
+func forbiddenAccess(method string) string {
+	if method == common.SetCmdMethod {
+		return common.DeviceResourceReadOnly
+	}
+	return common.DeviceResourceWriteOnly
+}
+
+func (d *Document) commandEvent(profileName string, resources []models.DeviceResource) *Schema {
+	readings := make([]*Schema, len(resources))
+	for i, dr := range resources {
+		name := schemaName(profileName, dr.Name, "Reading")
+		d.Components.Schemas[name] = resourceReading(profileName, dr)
+		readings[i] = ref(name)
+	}
+	items := &Schema{OneOf: readings}
+	if len(readings) == 1 {
+		items = readings[0]
+	}
+	return &Schema{AllOf: []*Schema{
+		ref(eventSchema),
+		{Type: "object", Properties: map[string]*Schema{
+			"profileName": {Type: "string", Enum: []interface{}{profileName}},
+			"readings":    {Type: "array", Items: items},
+		}},
+	}}
+}
+
+func commandRequest(resources []models.DeviceResource) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema, len(resources))}
+	for _, dr := range resources {
+		schema.Properties[dr.Name] = valueSchema(dr)
+		if dr.Properties.DefaultValue == "" {
+			schema.Required = append(schema.Required, dr.Name)
+		}
+	}
+	return schema
+}
+
+func oneOf(pcs []profileCommand) *Schema {
+	if len(pcs) == 1 {
+		return ref(pcs[0].schema)
+	}
+	schemas := make([]*Schema, len(pcs))
+	for i, pc := range pcs {
+		schemas[i] = ref(pc.schema)
+	}
+	return &Schema{OneOf: schemas}
+}
+
+func profileNames(pcs []profileCommand) string {
+	names := make([]string, len(pcs))
+	for i, pc := range pcs {
+		names[i] = pc.profileName
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,165 @@
+// Package openapi generates an OpenAPI 3 document describing the REST API of the
+// device service from its registered routes and the commands of its cached profiles.
+package openapi
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+)
+
+// Version is the version of the OpenAPI specification the documents follow
+const Version = "3.0.3"
+
+// Document is the root object of an OpenAPI document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info provides metadata about the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower case HTTP method
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a path or query parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the content of a request or response body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas of the document
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the subset of the OpenAPI schema object used to describe the API
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// New returns a document holding the schemas shared by the operations and no path
+func New(title string, version string, description string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Description: description, Version: version},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{Schemas: map[string]*Schema{
+			baseResponseSchema: baseResponse(),
+			eventSchema:        event(),
+			readingSchema:      reading(),
+		}},
+	}
+}
+
+var pathVariable = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// AddRoute documents the method of a route given as a gorilla/mux path template. The
+// variables of the template become required path parameters, and an operation already
+// documented for the path and method is kept.
+func (d *Document) AddRoute(route string, method string, summary string, tag string) {
+	path := pathVariable.ReplaceAllString(route, "{$1}")
+	if d.operation(path, method) != nil {
+		return
+	}
+
+	op := &Operation{
+		Summary:    summary,
+		Parameters: pathParameters(path),
+		Responses: map[string]*Response{
+			"200":     {Description: "OK"},
+			"default": errorResponse(),
+		},
+	}
+	if tag != "" {
+		op.Tags = []string{tag}
+	}
+	d.setOperation(path, method, op)
+}
+
+func (d *Document) operation(path string, method string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+func (d *Document) setOperation(path string, method string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+func pathParameters(path string) []Parameter {
+	var params []Parameter
+	for _, match := range pathVariable.FindAllStringSubmatch(path, -1) {
+		params = append(params, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	return params
+}
+
+func errorResponse() *Response {
+	return &Response{
+		Description: "Error",
+		Content:     jsonContent(ref(baseResponseSchema)),
+	}
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{contracts.ContentTypeJSON: {Schema: schema}}
+}
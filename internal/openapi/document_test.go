@@ -0,0 +1,106 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+const commandRoute = "/api/v2/device/name/{name}/{command}"
+
+func meterProfile(name string) models.DeviceProfile {
+	return models.DeviceProfile{
+		Name: name,
+		DeviceResources: []models.DeviceResource{
+			{Name: "voltage", Properties: models.PropertyValue{Type: "int16", ReadWrite: "R", Minimum: "0", Maximum: "400"}},
+			{Name: "current", Properties: models.PropertyValue{Type: contracts.ValueTypeFloat32, ReadWrite: "R"}},
+			{Name: "switch", Properties: models.PropertyValue{Type: contracts.ValueTypeBool, ReadWrite: "RW", DefaultValue: "true"}},
+			{Name: "ratio", Properties: models.PropertyValue{Type: contracts.ValueTypeUint8Array, ReadWrite: "W"}},
+		},
+		DeviceCommands: []models.ProfileResource{{
+			Name: "power",
+			Get:  []models.ResourceOperation{{DeviceResource: "voltage"}, {DeviceResource: "current"}},
+		}},
+	}
+}
+
+func TestAddRoute(t *testing.T) {
+	document := New("meter-service", "1.0.0", "")
+	document.AddRoute("/api/v2/discovery/job/id/{id:[0-9a-f-]+}", http.MethodDelete, "Cancel a discovery job", "discovery")
+
+	op := document.operation("/api/v2/discovery/job/id/{id}", http.MethodDelete)
+	require.NotNil(t, op)
+	assert.Equal(t, "Cancel a discovery job", op.Summary)
+	assert.Equal(t, []string{"discovery"}, op.Tags)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}, op.Parameters[0])
+	assert.Contains(t, op.Responses, "200")
+	assert.Contains(t, op.Responses, "default")
+}
+
+func TestAddProfileCommands(t *testing.T) {
+	document := New("meter-service", "1.0.0", "")
+	document.AddProfileCommands(commandRoute, []models.DeviceProfile{meterProfile("meter")})
+	document.AddRoute(commandRoute, http.MethodGet, "Read a command of a device", "device")
+
+	tests := []struct {
+		name   string
+		path   string
+		method string
+		exists bool
+	}{
+		{"read resource", "/api/v2/device/name/{name}/voltage", http.MethodGet, true},
+		{"set read-only resource", "/api/v2/device/name/{name}/voltage", http.MethodPut, false},
+		{"read device command", "/api/v2/device/name/{name}/power", http.MethodGet, true},
+		{"set device command without set", "/api/v2/device/name/{name}/power", http.MethodPut, false},
+		{"set resource", "/api/v2/device/name/{name}/switch", http.MethodPut, true},
+		{"read write-only resource", "/api/v2/device/name/{name}/ratio", http.MethodGet, false},
+		{"generic command route", "/api/v2/device/name/{name}/{command}", http.MethodGet, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exists, document.operation(tt.path, tt.method) != nil)
+		})
+	}
+
+	event := document.Components.Schemas["meter.power.Event"]
+	require.NotNil(t, event)
+	readings := event.AllOf[1].Properties["readings"].Items
+	assert.Equal(t, []*Schema{ref("meter.voltage.Reading"), ref("meter.current.Reading")}, readings.OneOf)
+	current := document.Components.Schemas["meter.current.Reading"].AllOf[1]
+	assert.Equal(t, []interface{}{contracts.ValueTypeFloat32}, current.Properties["valueType"].Enum)
+	assert.Equal(t, "byte", current.Properties["value"].Format)
+
+	request := document.Components.Schemas["meter.ratio.Request"]
+	require.NotNil(t, request)
+	assert.Equal(t, "array", request.Properties["ratio"].Type)
+	assert.Equal(t, "integer", request.Properties["ratio"].Items.Type)
+	assert.Equal(t, []string{"ratio"}, request.Required)
+	switchRequest := document.Components.Schemas["meter.switch.Request"]
+	assert.Equal(t, "boolean", switchRequest.Properties["switch"].Type)
+	assert.Empty(t, switchRequest.Required)
+
+	voltage := valueSchema(meterProfile("meter").DeviceResources[0])
+	assert.Equal(t, 0.0, *voltage.Minimum)
+	assert.Equal(t, 400.0, *voltage.Maximum)
+
+	_, err := json.Marshal(document)
+	assert.NoError(t, err)
+}
+
+func TestAddProfileCommands_SharedCommand(t *testing.T) {
+	document := New("meter-service", "1.0.0", "")
+	document.AddProfileCommands(commandRoute, []models.DeviceProfile{meterProfile("meter v2"), meterProfile("meter")})
+
+	op := document.operation("/api/v2/device/name/{name}/voltage", http.MethodGet)
+	require.NotNil(t, op)
+	assert.Equal(t, "Available to the devices using meter, meter v2", op.Description)
+	event := op.Responses["200"].Content[contracts.ContentTypeJSON].Schema.AllOf[1].Properties["event"]
+	assert.Equal(t, []*Schema{ref("meter.voltage.Event"), ref("meter_v2.voltage.Event")}, event.OneOf)
+}
@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+const (
+	baseResponseSchema = "BaseResponse"
+	eventSchema        = "Event"
+	readingSchema      = "Reading"
+)
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+var invalidSchemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// schemaName joins the parts into a component name, replacing the characters not
+// allowed in component names
+func schemaName(parts ...string) string {
+	return invalidSchemaNameChars.ReplaceAllString(strings.Join(parts, "."), "_")
+}
+
+func stringSchema() *Schema {
+	return &Schema{Type: "string"}
+}
+
+func int64Schema() *Schema {
+	return &Schema{Type: "integer", Format: "int64"}
+}
+
+func baseResponse() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"apiVersion": stringSchema(),
+			"requestId":  stringSchema(),
+			"message":    stringSchema(),
+			"statusCode": {Type: "integer"},
+		},
+	}
+}
+
+func event() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"apiVersion":  stringSchema(),
+			"id":          stringSchema(),
+			"deviceName":  stringSchema(),
+			"profileName": stringSchema(),
+			"created":     int64Schema(),
+			"origin":      int64Schema(),
+			"readings":    {Type: "array", Items: ref(readingSchema)},
+			"tags":        {Type: "object", AdditionalProperties: stringSchema()},
+		},
+		Required: []string{"id", "deviceName", "profileName", "origin", "readings"},
+	}
+}
+
+func reading() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"apiVersion":   stringSchema(),
+			"id":           stringSchema(),
+			"created":      int64Schema(),
+			"origin":       int64Schema(),
+			"deviceName":   stringSchema(),
+			"resourceName": stringSchema(),
+			"profileName":  stringSchema(),
+			"valueType":    stringSchema(),
+			"value":        stringSchema(),
+			"binaryValue":  {Type: "string", Format: "byte"},
+			"mediaType":    stringSchema(),
+			"tags":         {Type: "object", AdditionalProperties: stringSchema()},
+		},
+		Required: []string{"deviceName", "resourceName", "profileName", "valueType", "origin"},
+	}
+}
+
+// resourceReading describes the readings of the device resource, the value of a reading
+// is a string representation of the value of the resource type
+func resourceReading(profileName string, dr models.DeviceResource) *Schema {
+	valueType := normalizedType(dr.Properties.Type)
+	value := &Schema{Type: "string", Description: valueType + " value"}
+	switch valueType {
+	case contracts.ValueTypeFloat32, contracts.ValueTypeFloat64:
+		if dsModels.DefaultFloatEncoding == models.Base64Encoding {
+			value.Format = "byte"
+			value.Description = valueType + " value encoded in Base64"
+		}
+	case contracts.ValueTypeBinary:
+		value = nil
+	}
+
+	properties := map[string]*Schema{
+		"profileName":  {Type: "string", Enum: []interface{}{profileName}},
+		"resourceName": {Type: "string", Enum: []interface{}{dr.Name}},
+		"valueType":    {Type: "string", Enum: []interface{}{valueType}},
+	}
+	if value != nil {
+		properties["value"] = value
+	} else if dr.Properties.MediaType != "" {
+		properties["mediaType"] = &Schema{Type: "string", Enum: []interface{}{dr.Properties.MediaType}}
+	}
+	return &Schema{
+		Description: dr.Description,
+		AllOf:       []*Schema{ref(readingSchema), {Type: "object", Properties: properties}},
+	}
+}
+
+// valueSchema describes the JSON value of the device resource in a request setting it
+func valueSchema(dr models.DeviceResource) *Schema {
+	valueType := normalizedType(dr.Properties.Type)
+	if strings.HasSuffix(valueType, "Array") {
+		return &Schema{Type: "array", Description: dr.Description, Items: scalarSchema(strings.TrimSuffix(valueType, "Array"), models.PropertyValue{})}
+	}
+	schema := scalarSchema(valueType, dr.Properties)
+	if dr.Description != "" && schema.Description != "" {
+		schema.Description = dr.Description + ", " + schema.Description
+	} else if dr.Description != "" {
+		schema.Description = dr.Description
+	}
+	return schema
+}
+
+func scalarSchema(valueType string, properties models.PropertyValue) *Schema {
+	var schema *Schema
+	switch valueType {
+	case contracts.ValueTypeBool:
+		schema = &Schema{Type: "boolean"}
+	case contracts.ValueTypeInt8, contracts.ValueTypeInt16, contracts.ValueTypeInt32:
+		schema = &Schema{Type: "integer", Format: "int32"}
+	case contracts.ValueTypeInt64:
+		schema = &Schema{Type: "integer", Format: "int64"}
+	case contracts.ValueTypeUint8, contracts.ValueTypeUint16, contracts.ValueTypeUint32, contracts.ValueTypeUint64:
+		schema = &Schema{Type: "integer", Format: "int64", Minimum: number("0")}
+	case contracts.ValueTypeFloat32:
+		schema = &Schema{Type: "number", Format: "float"}
+	case contracts.ValueTypeFloat64:
+		schema = &Schema{Type: "number", Format: "double"}
+	case contracts.ValueTypeBinary:
+		return &Schema{Type: "string", Format: "byte"}
+	default:
+		return stringSchema()
+	}
+	if minimum := number(properties.Minimum); minimum != nil {
+		schema.Minimum = minimum
+	}
+	schema.Maximum = number(properties.Maximum)
+	if properties.DefaultValue != "" {
+		schema.Description = "defaults to " + properties.DefaultValue
+	}
+	return schema
+}
+
+func number(s string) *float64 {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func normalizedType(valueType string) string {
+	if normalized, err := contracts.NormalizeValueType(valueType); err == nil {
+		return normalized
+	}
+	return valueType
+}
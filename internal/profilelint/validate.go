@@ -0,0 +1,235 @@
+// Package profilelint checks the device profiles before they are used, so that the
+// mistakes in a profile are reported when it is added rather than when a command
+// using it fails.
+package profilelint
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+)
+
+// Problem is a mistake found in a profile. Location is the path of the faulty
+// field, such as deviceCommands[all].get[1].deviceResource.
+type Problem struct {
+	Location string
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Location, p.Message)
+}
+
+// Validate returns a KindContractInvalid error listing all the problems of the
+// profile, or nil if the profile has none
+func Validate(profile models.DeviceProfile) errors.EdgeX {
+	problems := Lint(profile)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(problems))
+	for i, p := range problems {
+		messages[i] = p.String()
+	}
+	errMsg := fmt.Sprintf("profile %s is invalid: %s", profile.Name, strings.Join(messages, "; "))
+	return errors.NewCommonEdgeX(errors.KindContractInvalid, errMsg, nil)
+}
+
+// Lint returns all the problems of the profile, in the order of the profile fields
+func Lint(profile models.DeviceProfile) []Problem {
+	l := linter{resources: make(map[string]models.DeviceResource), commands: make(map[string]models.ProfileResource)}
+	if profile.Name == "" {
+		l.report("name", "is empty")
+	}
+
+	for i, r := range profile.DeviceResources {
+		location := fmt.Sprintf("deviceResources[%s]", name(r.Name, i))
+		if r.Name == "" {
+			l.report(location+".name", "is empty")
+		} else if _, ok := l.resources[r.Name]; ok {
+			l.report(location+".name", "is a duplicate device resource name")
+		} else {
+			l.resources[r.Name] = r
+		}
+		l.lintProperties(location+".properties", r.Properties)
+	}
+
+	for i, c := range profile.DeviceCommands {
+		location := fmt.Sprintf("deviceCommands[%s]", name(c.Name, i))
+		if c.Name == "" {
+			l.report(location+".name", "is empty")
+		} else if _, ok := l.commands[c.Name]; ok {
+			l.report(location+".name", "is a duplicate device command name")
+		} else {
+			l.commands[c.Name] = c
+		}
+		for j, ro := range c.Get {
+			l.lintResourceOperation(fmt.Sprintf("%s.get[%d]", location, j), ro, common.DeviceResourceWriteOnly)
+		}
+		for j, ro := range c.Set {
+			l.lintResourceOperation(fmt.Sprintf("%s.set[%d]", location, j), ro, common.DeviceResourceReadOnly)
+		}
+	}
+
+	for i, c := range profile.CoreCommands {
+		l.lintCoreCommand(fmt.Sprintf("coreCommands[%s]", name(c.Name, i)), c)
+	}
+	return l.problems
+}
+
+type linter struct {
+	resources map[string]models.DeviceResource
+	commands  map[string]models.ProfileResource
+	problems  []Problem
+}
+
+func (l *linter) report(location string, format string, args ...interface{}) {
+	l.problems = append(l.problems, Problem{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lintProperties(location string, properties models.PropertyValue) {
+	valueType, err := contracts.NormalizeValueType(properties.Type)
+	if err != nil {
+		l.report(location+".type", "unsupported value type %q", properties.Type)
+	}
+
+	switch properties.ReadWrite {
+	case "", common.DeviceResourceReadOnly, common.DeviceResourceWriteOnly, "RW":
+	default:
+		l.report(location+".readWrite", "unsupported value %q, expected R, W or RW", properties.ReadWrite)
+	}
+
+	if !isNumeric(valueType) {
+		return
+	}
+	minimum, minOk := l.parseNumber(location+".minimum", properties.Minimum)
+	maximum, maxOk := l.parseNumber(location+".maximum", properties.Maximum)
+	if minOk && maxOk && minimum > maximum {
+		l.report(location, "minimum %s is greater than maximum %s", properties.Minimum, properties.Maximum)
+	}
+}
+
+// parseNumber reports a value which is set but is not a number
+func (l *linter) parseNumber(location string, value string) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.report(location, "%q is not a number", value)
+		return 0, false
+	}
+	return n, true
+}
+
+// lintResourceOperation checks the resource operation of a device command, the
+// device resource must exist and must not be restricted to the forbidden access
+func (l *linter) lintResourceOperation(location string, ro models.ResourceOperation, forbidden string) {
+	r, ok := l.resources[ro.DeviceResource]
+	if !ok {
+		l.report(location+".deviceResource", "device resource %q is not defined", ro.DeviceResource)
+		return
+	}
+	if r.Properties.ReadWrite == forbidden {
+		access := "read-only"
+		if forbidden == common.DeviceResourceWriteOnly {
+			access = "write-only"
+		}
+		l.report(location+".deviceResource", "device resource %q is %s", ro.DeviceResource, access)
+	}
+
+	valueType, err := contracts.NormalizeValueType(r.Properties.Type)
+	if err != nil {
+		// already reported for the device resource
+		return
+	}
+	keys := make([]string, 0, len(ro.Mappings))
+	for key := range ro.Mappings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !matchesType(key, valueType) {
+			l.report(fmt.Sprintf("%s.mappings[%s]", location, key), "is not a %s value of device resource %q", valueType, ro.DeviceResource)
+		}
+	}
+}
+
+// lintCoreCommand checks that the core command is backed by a device command or a
+// device resource supporting its methods
+func (l *linter) lintCoreCommand(location string, c models.Command) {
+	if dc, ok := l.commands[c.Name]; ok {
+		if c.Get && len(dc.Get) == 0 {
+			l.report(location+".get", "device command %q has no get operation", c.Name)
+		}
+		if c.Put && len(dc.Set) == 0 {
+			l.report(location+".put", "device command %q has no set operation", c.Name)
+		}
+		return
+	}
+	if r, ok := l.resources[c.Name]; ok {
+		if c.Get && r.Properties.ReadWrite == common.DeviceResourceWriteOnly {
+			l.report(location+".get", "device resource %q is write-only", c.Name)
+		}
+		if c.Put && r.Properties.ReadWrite == common.DeviceResourceReadOnly {
+			l.report(location+".put", "device resource %q is read-only", c.Name)
+		}
+		return
+	}
+	l.report(location+".name", "no device command or device resource named %q", c.Name)
+}
+
+func name(name string, index int) string {
+	if name == "" {
+		return strconv.Itoa(index)
+	}
+	return name
+}
+
+func isNumeric(valueType string) bool {
+	switch valueType {
+	case contracts.ValueTypeUint8, contracts.ValueTypeUint16, contracts.ValueTypeUint32, contracts.ValueTypeUint64,
+		contracts.ValueTypeInt8, contracts.ValueTypeInt16, contracts.ValueTypeInt32, contracts.ValueTypeInt64,
+		contracts.ValueTypeFloat32, contracts.ValueTypeFloat64:
+		return true
+	}
+	return false
+}
+
+// matchesType tells whether a mapping key can be a value of the type. Only the
+// scalar types are checked.
+func matchesType(value string, valueType string) bool {
+	var err error
+	switch valueType {
+	case contracts.ValueTypeBool:
+		_, err = strconv.ParseBool(value)
+	case contracts.ValueTypeUint8:
+		_, err = strconv.ParseUint(value, 10, 8)
+	case contracts.ValueTypeUint16:
+		_, err = strconv.ParseUint(value, 10, 16)
+	case contracts.ValueTypeUint32:
+		_, err = strconv.ParseUint(value, 10, 32)
+	case contracts.ValueTypeUint64:
+		_, err = strconv.ParseUint(value, 10, 64)
+	case contracts.ValueTypeInt8:
+		_, err = strconv.ParseInt(value, 10, 8)
+	case contracts.ValueTypeInt16:
+		_, err = strconv.ParseInt(value, 10, 16)
+	case contracts.ValueTypeInt32:
+		_, err = strconv.ParseInt(value, 10, 32)
+	case contracts.ValueTypeInt64:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case contracts.ValueTypeFloat32:
+		_, err = strconv.ParseFloat(value, 32)
+	case contracts.ValueTypeFloat64:
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	return err == nil
+}
@@ -0,0 +1,63 @@
+package profilelint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+func validProfile() models.DeviceProfile {
+	return models.DeviceProfile{
+		Name: "meter",
+		DeviceResources: []models.DeviceResource{
+			{Name: "power", Properties: models.PropertyValue{Type: "Float32", ReadWrite: "R", Minimum: "0", Maximum: "100"}},
+			{Name: "switch", Properties: models.PropertyValue{Type: "bool", ReadWrite: "RW"}},
+		},
+		DeviceCommands: []models.ProfileResource{
+			{
+				Name: "status",
+				Get: []models.ResourceOperation{
+					{DeviceResource: "power"},
+					{DeviceResource: "switch", Mappings: map[string]string{"true": "on", "false": "off"}},
+				},
+			},
+		},
+		CoreCommands: []models.Command{{Name: "status", Get: true}, {Name: "switch", Get: true, Put: true}},
+	}
+}
+
+func TestLintValidProfile(t *testing.T) {
+	assert.Empty(t, Lint(validProfile()))
+	assert.NoError(t, Validate(validProfile()))
+}
+
+func TestLintReportsAllProblems(t *testing.T) {
+	profile := validProfile()
+	profile.DeviceResources[0].Properties.Minimum = "200"
+	profile.DeviceResources = append(profile.DeviceResources,
+		models.DeviceResource{Name: "mode", Properties: models.PropertyValue{Type: "Enum", ReadWrite: "X"}})
+	profile.DeviceCommands[0].Get[1].Mappings["yes"] = "on"
+	profile.DeviceCommands = append(profile.DeviceCommands, models.ProfileResource{
+		Name: "set",
+		Set:  []models.ResourceOperation{{DeviceResource: "power"}, {DeviceResource: "voltage"}},
+	})
+	profile.CoreCommands = append(profile.CoreCommands, models.Command{Name: "status", Put: true}, models.Command{Name: "reset", Put: true})
+
+	assert.Equal(t, []Problem{
+		{"deviceResources[power].properties", "minimum 200 is greater than maximum 100"},
+		{"deviceResources[mode].properties.type", `unsupported value type "Enum"`},
+		{"deviceResources[mode].properties.readWrite", `unsupported value "X", expected R, W or RW`},
+		{"deviceCommands[status].get[1].mappings[yes]", `is not a Bool value of device resource "switch"`},
+		{"deviceCommands[set].set[0].deviceResource", `device resource "power" is read-only`},
+		{"deviceCommands[set].set[1].deviceResource", `device resource "voltage" is not defined`},
+		{"coreCommands[status].put", `device command "status" has no set operation`},
+		{"coreCommands[reset].name", `no device command or device resource named "reset"`},
+	}, Lint(profile))
+
+	err := Validate(profile)
+	assert.Equal(t, errors.KindContractInvalid, errors.Kind(err))
+	assert.Contains(t, err.Error(), "deviceCommands[set].set[1].deviceResource")
+}
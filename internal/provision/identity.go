@@ -0,0 +1,88 @@
+package provision
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// IdentityKeys returns the identity keys declared by the discovered device followed
+// by the ones the ProvisionWatcher adds, without duplicates. As identifiers, keys are
+// either protocol-qualified or plain property names.
+func IdentityKeys(d dsModels.DiscoveredDevice, pw models.ProvisionWatcher) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, k := range append(append([]string{}, d.IdentityKeys...), pw.IdentityKeys...) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// FindByIdentity returns the first device having the same identity as the discovered
+// device. A device has the same identity if, for every key, one of its values equals
+// one of the values of the discovered device. Devices missing a key never match, and
+// nothing matches without keys.
+func FindByIdentity(d dsModels.DiscoveredDevice, keys []string, devices []models.Device) (models.Device, bool) {
+	if len(keys) == 0 {
+		return models.Device{}, false
+	}
+
+	identity := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		values := lookupProtocols(d.Protocols, key)
+		if len(values) == 0 {
+			return models.Device{}, false
+		}
+		identity[key] = values
+	}
+
+	for _, device := range devices {
+		if sameIdentity(identity, device.Protocols) {
+			return device, true
+		}
+	}
+	return models.Device{}, false
+}
+
+// MergeProtocols returns the existing protocols updated with the discovered protocol
+// properties, and whether any property changed. Properties only found in the
+// existing protocols are kept.
+func MergeProtocols(existing, discovered map[string]models.ProtocolProperties) (map[string]models.ProtocolProperties, bool) {
+	changed := false
+	merged := make(map[string]models.ProtocolProperties, len(existing))
+	for name, properties := range existing {
+		merged[name] = make(models.ProtocolProperties, len(properties))
+		for k, v := range properties {
+			merged[name][k] = v
+		}
+	}
+	for name, properties := range discovered {
+		if _, ok := merged[name]; !ok {
+			merged[name] = make(models.ProtocolProperties, len(properties))
+		}
+		for k, v := range properties {
+			if current, ok := merged[name][k]; !ok || current != v {
+				merged[name][k] = v
+				changed = true
+			}
+		}
+	}
+	return merged, changed
+}
+
+func sameIdentity(identity map[string][]string, protocols map[string]models.ProtocolProperties) bool {
+	for key, values := range identity {
+		found := false
+		for _, v := range lookupProtocols(protocols, key) {
+			for _, expected := range values {
+				found = found || v == expected
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+func TestIdentityKeys(t *testing.T) {
+	d := dsModels.DiscoveredDevice{IdentityKeys: []string{"Serial", "MAC"}}
+	pw := models.ProvisionWatcher{IdentityKeys: []string{"MAC", "other.Serial", ""}}
+
+	assert.Equal(t, []string{"Serial", "MAC", "other.Serial"}, IdentityKeys(d, pw))
+	assert.Empty(t, IdentityKeys(dsModels.DiscoveredDevice{}, models.ProvisionWatcher{}))
+}
+
+func TestFindByIdentity(t *testing.T) {
+	devices := []models.Device{
+		{Name: "meter-a", Protocols: map[string]models.ProtocolProperties{"other": {"Serial": "SN-000"}}},
+		{Name: "meter-b", Protocols: map[string]models.ProtocolProperties{
+			"modbus-tcp": {"Address": "192.168.1.10:502", "UnitID": "7"},
+			"other":      {"Serial": "SN-001"},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		keys     []string
+		expected string
+	}{
+		{"no keys", nil, ""},
+		{"plain key", []string{"Serial"}, "meter-b"},
+		{"qualified key", []string{"other.Serial"}, "meter-b"},
+		{"all keys must match", []string{"Serial", "modbus-tcp.Address"}, ""},
+		{"key missing in discovered device", []string{"MAC"}, ""},
+		{"several keys", []string{"Serial", "UnitID"}, "meter-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device, ok := FindByIdentity(discovered, tt.keys, devices)
+			assert.Equal(t, tt.expected != "", ok)
+			assert.Equal(t, tt.expected, device.Name)
+		})
+	}
+}
+
+func TestMergeProtocols(t *testing.T) {
+	existing := map[string]models.ProtocolProperties{
+		"modbus-tcp": {"Address": "192.168.1.10:502", "UnitID": "7", "Timeout": "5"},
+	}
+
+	merged, changed := MergeProtocols(existing, discovered.Protocols)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]models.ProtocolProperties{
+		"modbus-tcp": {"Address": "192.168.1.20:502", "UnitID": "7", "Timeout": "5"},
+		"other":      {"Serial": "SN-001"},
+	}, merged)
+	assert.Equal(t, "192.168.1.10:502", existing["modbus-tcp"]["Address"], "existing protocols must not be modified")
+
+	_, changed = MergeProtocols(merged, discovered.Protocols)
+	assert.False(t, changed)
+}
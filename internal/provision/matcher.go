@@ -0,0 +1,239 @@
+package provision
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// LabelKey is the identifier key matching the labels of the discovered device
+// instead of a protocol property
+const LabelKey = "@label"
+
+// Prefixes of the identifier values selecting how the value is matched. Identifiers
+// without prefix are regular expressions, blocking identifiers without prefix must
+// be equal to the value.
+const (
+	RegexPrefix = "regex:"
+	CIDRPrefix  = "cidr:"
+	RangePrefix = "range:"
+)
+
+// Decision tells whether a ProvisionWatcher accepts a discovered device and why
+type Decision struct {
+	Watcher  string
+	Accepted bool
+	Reasons  []string
+}
+
+func (d *Decision) reject(format string, args ...interface{}) {
+	d.Accepted = false
+	d.Reasons = append(d.Reasons, fmt.Sprintf(format, args...))
+}
+
+// Match evaluates the discovered device against the ProvisionWatcher. Identifier
+// keys are either protocol-qualified, such as "modbus-tcp.Address", or plain
+// property names matching the property in any protocol of the device.
+//
+// The identifiers are compared across all the protocols of the device: plain
+// identifiers may be matched by properties of different protocols, whereas they all
+// had to match within a single protocol before. Qualify the keys to require a
+// given protocol.
+//
+// A device is accepted by an unlocked watcher if every identifier matches and no
+// blocking identifier does.
+func Match(d dsModels.DiscoveredDevice, pw models.ProvisionWatcher) Decision {
+	decision := Decision{Watcher: pw.Name, Accepted: true}
+	if pw.AdminState == models.Locked {
+		decision.reject("provision watcher is locked")
+	}
+
+	for _, key := range sortedKeys(pw.Identifiers) {
+		expr := pw.Identifiers[key]
+		match, err := parseMatcher(expr, RegexPrefix)
+		if err != nil {
+			decision.reject("identifier %s has an invalid value %s: %v", key, expr, err)
+			continue
+		}
+		values := lookup(d, key)
+		if len(values) == 0 {
+			decision.reject("identifier %s not found", key)
+			continue
+		}
+		if _, ok := firstMatch(match, values); !ok {
+			decision.reject("identifier %s value %s does not match %s", key, strings.Join(values, ","), expr)
+		}
+	}
+
+	for _, key := range sortedKeys(pw.BlockingIdentifiers) {
+		values := lookup(d, key)
+		for _, expr := range pw.BlockingIdentifiers[key] {
+			match, err := parseMatcher(expr, "")
+			if err != nil {
+				decision.reject("blocking identifier %s has an invalid value %s: %v", key, expr, err)
+				continue
+			}
+			if value, ok := firstMatch(match, values); ok {
+				decision.reject("blocking identifier %s value %s matches %s", key, value, expr)
+			}
+		}
+	}
+
+	if decision.Accepted {
+		decision.Reasons = append(decision.Reasons, "all identifiers matched")
+	}
+	return decision
+}
+
+// Explain evaluates the discovered device against all the ProvisionWatchers
+func Explain(d dsModels.DiscoveredDevice, pws []models.ProvisionWatcher) []Decision {
+	decisions := make([]Decision, len(pws))
+	for i, pw := range pws {
+		decisions[i] = Match(d, pw)
+	}
+	return decisions
+}
+
+// FirstAccepting returns the first ProvisionWatcher accepting the discovered device
+func FirstAccepting(d dsModels.DiscoveredDevice, pws []models.ProvisionWatcher) (models.ProvisionWatcher, bool) {
+	for _, pw := range pws {
+		if Match(d, pw).Accepted {
+			return pw, true
+		}
+	}
+	return models.ProvisionWatcher{}, false
+}
+
+// lookup returns the values of the identifier key in the discovered device
+func lookup(d dsModels.DiscoveredDevice, key string) []string {
+	if key == LabelKey {
+		return d.Labels
+	}
+	return lookupProtocols(d.Protocols, key)
+}
+
+// lookupProtocols returns the values of the protocol property key, either
+// protocol-qualified or plain
+func lookupProtocols(protocols map[string]models.ProtocolProperties, key string) []string {
+	if i := strings.Index(key, "."); i > 0 {
+		if protocol, ok := protocols[key[:i]]; ok {
+			if value, ok := protocol[key[i+1:]]; ok {
+				return []string{value}
+			}
+			return nil
+		}
+	}
+
+	var values []string
+	for _, name := range sortedKeys(protocols) {
+		if value, ok := protocols[name][key]; ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func firstMatch(match func(string) bool, values []string) (string, bool) {
+	for _, v := range values {
+		if match(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// parseMatcher parses the identifier value, defaultPrefix applies to values
+// without prefix and values are compared for equality if it is empty.
+func parseMatcher(expr string, defaultPrefix string) (func(string) bool, error) {
+	prefix := defaultPrefix
+	for _, p := range []string{RegexPrefix, CIDRPrefix, RangePrefix} {
+		if strings.HasPrefix(expr, p) {
+			prefix, expr = p, strings.TrimPrefix(expr, p)
+			break
+		}
+	}
+
+	switch prefix {
+	case RegexPrefix:
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	case CIDRPrefix:
+		_, network, err := net.ParseCIDR(expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(value string) bool {
+			if host, _, err := net.SplitHostPort(value); err == nil {
+				value = host
+			}
+			ip := net.ParseIP(value)
+			return ip != nil && network.Contains(ip)
+		}, nil
+	case RangePrefix:
+		return parseRange(expr)
+	default:
+		return func(value string) bool {
+			return value == expr
+		}, nil
+	}
+}
+
+// parseRange parses an inclusive numeric range such as 10..20, either bound may be omitted
+func parseRange(expr string) (func(string) bool, error) {
+	bounds := strings.SplitN(expr, "..", 2)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("range %s should be formatted as min..max", expr)
+	}
+	parseBound := func(s string) (*float64, error) {
+		if strings.TrimSpace(s) == "" {
+			return nil, nil
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return &v, err
+	}
+	min, err := parseBound(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+	max, err := parseBound(bounds[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value string) bool {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		return (min == nil || v >= *min) && (max == nil || v <= *max)
+	}, nil
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string][]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]models.ProtocolProperties:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
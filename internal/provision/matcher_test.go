@@ -0,0 +1,77 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+var discovered = dsModels.DiscoveredDevice{
+	Name: "meter-1",
+	Protocols: map[string]models.ProtocolProperties{
+		"modbus-tcp": {"Address": "192.168.1.20:502", "UnitID": "7"},
+		"other":      {"Serial": "SN-001"},
+	},
+	Labels: []string{"power", "floor-1"},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		identifiers map[string]string
+		blocking    map[string][]string
+		adminState  models.AdminState
+		accepted    bool
+	}{
+		{"regex", map[string]string{"Serial": "^SN-"}, nil, models.Unlocked, true},
+		{"regex mismatch", map[string]string{"Serial": "^XX-"}, nil, models.Unlocked, false},
+		{"qualified key", map[string]string{"other.Serial": "SN-001"}, nil, models.Unlocked, true},
+		{"qualified key wrong protocol", map[string]string{"modbus-tcp.Serial": "SN-001"}, nil, models.Unlocked, false},
+		{"across protocols", map[string]string{"Serial": "^SN-", "UnitID": "7"}, nil, models.Unlocked, true},
+		{"missing identifier", map[string]string{"MAC": ".*"}, nil, models.Unlocked, false},
+		{"cidr", map[string]string{"Address": "cidr:192.168.1.0/24"}, nil, models.Unlocked, true},
+		{"cidr mismatch", map[string]string{"Address": "cidr:10.0.0.0/8"}, nil, models.Unlocked, false},
+		{"range", map[string]string{"modbus-tcp.UnitID": "range:1..10"}, nil, models.Unlocked, true},
+		{"open range", map[string]string{"UnitID": "range:8.."}, nil, models.Unlocked, false},
+		{"label", map[string]string{LabelKey: "^floor-"}, nil, models.Unlocked, true},
+		{"blocked exact", map[string]string{"Serial": ".*"}, map[string][]string{"Serial": {"SN-001"}}, models.Unlocked, false},
+		{"blocked regex is exact by default", nil, map[string][]string{"Serial": {"SN-.*"}}, models.Unlocked, true},
+		{"blocked regex", nil, map[string][]string{"Serial": {"regex:SN-.*"}}, models.Unlocked, false},
+		{"blocked label", nil, map[string][]string{LabelKey: {"power"}}, models.Unlocked, false},
+		{"locked watcher", map[string]string{"Serial": ".*"}, nil, models.Locked, false},
+		{"invalid regex", map[string]string{"Serial": "("}, nil, models.Unlocked, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := models.ProvisionWatcher{
+				Name:                "watcher",
+				Identifiers:         tt.identifiers,
+				BlockingIdentifiers: tt.blocking,
+				AdminState:          tt.adminState,
+			}
+			decision := Match(discovered, pw)
+			assert.Equal(t, tt.accepted, decision.Accepted, decision.Reasons)
+			assert.NotEmpty(t, decision.Reasons)
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	pws := []models.ProvisionWatcher{
+		{Name: "locked", AdminState: models.Locked},
+		{Name: "serial", Identifiers: map[string]string{"Serial": "^SN-"}, AdminState: models.Unlocked},
+	}
+
+	decisions := Explain(discovered, pws)
+	assert.Len(t, decisions, 2)
+	assert.False(t, decisions[0].Accepted)
+	assert.Equal(t, []string{"provision watcher is locked"}, decisions[0].Reasons)
+	assert.True(t, decisions[1].Accepted)
+
+	pw, ok := FirstAccepting(discovered, pws)
+	assert.True(t, ok)
+	assert.Equal(t, "serial", pw.Name)
+}
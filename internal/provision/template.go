@@ -0,0 +1,91 @@
+package provision
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// protocolPlaceholder prefixes the placeholders of the name template looking up a
+// property in any protocol of the discovered device, such as {protocol.serial}
+const protocolPlaceholder = "protocol."
+
+var (
+	placeholderRegex = regexp.MustCompile(`{([^{}]+)}`)
+	// device names may only contain RFC 3986 unreserved characters
+	reservedCharsRegex = regexp.MustCompile(`[^A-Za-z0-9\-_.~]+`)
+)
+
+// DeviceName renders the name template of the ProvisionWatcher for the discovered
+// device. The placeholders are {name} for the discovered name, {protocol.<property>}
+// for a property of any protocol and {<protocol>.<property>} for a property of the
+// given protocol, property names are matched case-insensitively. Characters not
+// allowed in device names are replaced by '-'.
+func DeviceName(d dsModels.DiscoveredDevice, pw models.ProvisionWatcher) (string, error) {
+	if pw.DeviceNameTemplate == "" {
+		return d.Name, nil
+	}
+
+	var missing []string
+	name := placeholderRegex.ReplaceAllStringFunc(pw.DeviceNameTemplate, func(placeholder string) string {
+		key := strings.TrimSpace(placeholder[1 : len(placeholder)-1])
+		value, ok := placeholderValue(d, key)
+		if !ok {
+			missing = append(missing, key)
+			return ""
+		}
+		return reservedCharsRegex.ReplaceAllString(value, "-")
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("device name template %s of provision watcher %s: %s not found in discovered device %s",
+			pw.DeviceNameTemplate, pw.Name, strings.Join(missing, ","), d.Name)
+	}
+	return name, nil
+}
+
+// DeviceLabels returns the labels of the discovered device followed by the labels
+// the ProvisionWatcher adds, without duplicates.
+func DeviceLabels(d dsModels.DiscoveredDevice, pw models.ProvisionWatcher) []string {
+	var labels []string
+	seen := make(map[string]bool)
+	for _, l := range append(append([]string{}, d.Labels...), pw.DeviceLabels...) {
+		if !seen[l] {
+			seen[l] = true
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// placeholderValue returns the value of the placeholder key for the discovered device.
+// A property matching the key exactly wins, otherwise the first property matching it
+// case-insensitively in the order of the property names.
+func placeholderValue(d dsModels.DiscoveredDevice, key string) (string, bool) {
+	if key == "name" {
+		return d.Name, d.Name != ""
+	}
+
+	protocols := sortedKeys(d.Protocols)
+	if strings.HasPrefix(key, protocolPlaceholder) {
+		key = strings.TrimPrefix(key, protocolPlaceholder)
+	} else if i := strings.Index(key, "."); i > 0 {
+		if _, ok := d.Protocols[key[:i]]; ok {
+			protocols, key = []string{key[:i]}, key[i+1:]
+		}
+	}
+
+	for _, name := range protocols {
+		if value, ok := d.Protocols[name][key]; ok {
+			return value, true
+		}
+		for _, property := range sortedKeys(map[string]string(d.Protocols[name])) {
+			if strings.EqualFold(property, key) {
+				return d.Protocols[name][property], true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+func TestDeviceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+		valid    bool
+	}{
+		{"no template", "", "meter-1", true},
+		{"any protocol", "meter-{protocol.serial}", "meter-SN-001", true},
+		{"qualified protocol", "{modbus-tcp.UnitID}-{name}", "7-meter-1", true},
+		{"reserved characters", "meter-{protocol.Address}", "meter-192.168.1.20-502", true},
+		{"missing property", "meter-{protocol.mac}", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := DeviceName(discovered, models.ProvisionWatcher{Name: "watcher", DeviceNameTemplate: tt.template})
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
+
+func TestDeviceNameCaseInsensitive(t *testing.T) {
+	d := discovered
+	d.Protocols = map[string]models.ProtocolProperties{
+		"other": {"SERIAL": "SN-002", "Serial": "SN-003"},
+		"tcp":   {"serial": "SN-001"},
+	}
+	pw := models.ProvisionWatcher{Name: "watcher", DeviceNameTemplate: "{protocol.Serial}-{tcp.SERIAL}"}
+
+	// the exact match wins, then the first property name in order
+	for i := 0; i < 10; i++ {
+		name, err := DeviceName(d, pw)
+		require.NoError(t, err)
+		assert.Equal(t, "SN-003-SN-001", name)
+	}
+	pw.DeviceNameTemplate = "{other.serial}"
+	name, err := DeviceName(d, pw)
+	require.NoError(t, err)
+	assert.Equal(t, "SN-002", name)
+}
+
+func TestDeviceLabels(t *testing.T) {
+	pw := models.ProvisionWatcher{DeviceLabels: []string{"floor-1", "gateway-a"}}
+	assert.Equal(t, []string{"power", "floor-1", "gateway-a"}, DeviceLabels(discovered, pw))
+}
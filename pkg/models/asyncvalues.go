@@ -11,3 +11,38 @@ type AsyncValues struct {
 	DeviceName    string
 	CommandValues []*CommandValue
 }
+
+// RejectionReason tells why an asynchronous reading was rejected
+type RejectionReason string
+
+const (
+	// RejectUnknownDevice is used when the Device of the AsyncValues is not managed by the service
+	RejectUnknownDevice RejectionReason = "UnknownDevice"
+	// RejectUnknownResource is used when the Device Resource is not defined in the Device Profile
+	RejectUnknownResource RejectionReason = "UnknownResource"
+	// RejectTransformFailed is used when the value could not be transformed, a reading with
+	// the failure message is published instead
+	RejectTransformFailed RejectionReason = "TransformFailed"
+	// RejectAssertionFailed is used when the value does not match the assertion of the
+	// Device Resource, a reading with the failure message is published instead
+	RejectAssertionFailed RejectionReason = "AssertionFailed"
+	// RejectSinkFailed is used when the event could not be sent to core-data, for the
+	// values not already rejected for another reason
+	RejectSinkFailed RejectionReason = "SinkFailed"
+)
+
+// AsyncRejection reports an asynchronous reading that was rejected or could not be delivered
+type AsyncRejection struct {
+	DeviceName string
+	// Value is the CommandValue as pushed by the ProtocolDriver
+	Value  CommandValue
+	Reason RejectionReason
+	Err    error
+}
+
+// AsyncRejectionHandler can optionally be implemented by the ProtocolDriver to learn
+// about the rejected asynchronous readings. HandleAsyncRejection is called from the
+// goroutines processing the readings, so it should return quickly.
+type AsyncRejectionHandler interface {
+	HandleAsyncRejection(rejection AsyncRejection)
+}
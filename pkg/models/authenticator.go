@@ -0,0 +1,13 @@
+package models
+
+import "net/http"
+
+// Authenticator authenticates the REST requests sent to the device service by a method,
+// such as static API keys or signed tokens. The device service can add its own methods
+// to the ones named in the auth policies of its configuration.
+type Authenticator interface {
+	// Authenticate returns the identity of the client sending the request. It returns
+	// false if the request carries no credentials of the method, and an error if it
+	// carries invalid ones.
+	Authenticate(request *http.Request) (identity string, ok bool, err error)
+}
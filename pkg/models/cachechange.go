@@ -0,0 +1,38 @@
+package models
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+)
+
+// ChangeType is the kind of change made to a cached entity
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "Added"
+	ChangeUpdated ChangeType = "Updated"
+	ChangeRemoved ChangeType = "Removed"
+)
+
+// DeviceChange is published for every change of the device cache. Before is nil
+// for an added device and After is nil for a removed device.
+type DeviceChange struct {
+	Type   ChangeType
+	Before *models.Device
+	After  *models.Device
+}
+
+// ProfileChange is published for every change of the profile cache. Before is nil
+// for an added profile and After is nil for a removed profile.
+type ProfileChange struct {
+	Type   ChangeType
+	Before *models.DeviceProfile
+	After  *models.DeviceProfile
+}
+
+// ProvisionWatcherChange is published for every change of the provision watcher
+// cache. Before is nil for an added watcher and After is nil for a removed watcher.
+type ProvisionWatcherChange struct {
+	Type   ChangeType
+	Before *models.ProvisionWatcher
+	After  *models.ProvisionWatcher
+}
@@ -8,6 +8,8 @@
 package models
 
 import (
+	"context"
+
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
 )
 
@@ -21,10 +23,24 @@ type ProtocolDiscovery interface {
 	Discover()
 }
 
+// ContextDiscovery can optionally be implemented by the ProtocolDiscovery to run
+// discovery jobs. DiscoverContext runs a protocol specific discovery with the
+// driver-specific options of the discovery request, such as a subnet, a port range
+// or a timeout, and returns once the discovery is complete or ctx is cancelled.
+// The results are written to the channel passed via ProtocolDriver.Initialize()
+// as with Discover.
+type ContextDiscovery interface {
+	DiscoverContext(ctx context.Context, options map[string]string) error
+}
+
 // DiscoveredDevice defines the required information for a found device.
 type DiscoveredDevice struct {
 	Name        string
 	Protocols   map[string]models.ProtocolProperties
 	Description string
 	Labels      []string
+	// IdentityKeys name the protocol properties identifying the device, such as a
+	// serial number or a MAC address. A discovered device whose identity matches an
+	// existing device updates it rather than being added again.
+	IdentityKeys []string
 }
@@ -55,3 +55,12 @@ type ProtocolDriver interface {
 	// when a Device associated with this Device Service is removed
 	RemoveDevice(deviceName string, protocols map[string]models.ProtocolProperties) error
 }
+
+// ProfileAwareDriver can optionally be implemented by the ProtocolDriver to learn
+// about the changes of the device profiles, for example to rebuild the register maps
+// it compiles from the DeviceResource Attributes. UpdateProfile is called after a
+// profile used by the Device Service is updated and when a Device switches to
+// another profile.
+type ProfileAwareDriver interface {
+	UpdateProfile(profile models.DeviceProfile) error
+}
@@ -11,7 +11,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"regexp"
+	"net/http"
 	"sync"
 	"time"
 
@@ -20,59 +20,92 @@ import (
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos"
 	commonDTO "github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/common"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
+	edgexErr "github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/async"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autodiscovery"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/command"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/provision"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/transformer"
-	"github.com/tuya/tuya-edge-driver-sdk-go/logger"
 	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
 
 // processAsyncResults processes readings that are pushed from
 // a DS implementation. Each is reading is optionally transformed
 // before being pushed to Core Data.
-// In this function, AsyncBufferSize is used to create a buffer for
-// processing AsyncValues concurrently, so that events may arrive
-// out-of-order in core-data / app service when AsyncBufferSize value
-// is greater than or equal to two. Alternatively, we can process
-// AsyncValues one by one in the same order by changing the AsyncBufferSize
-// value to one.
+// The AsyncValues are handed over to the async worker pool, which processes
+// the AsyncValues of a device in order. Service.AsyncWorkers bounds the number
+// of AsyncValues processed concurrently and Service.AsyncOverflowPolicy decides
+// what happens when the queue of a worker is full.
 func (s *DeviceService) processAsyncResults(ctx context.Context, wg *sync.WaitGroup) {
 	wg.Add(1)
 	defer func() {
 		wg.Done()
 	}()
 
-	working := make(chan bool, s.config.Service.AsyncBufferSize)
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case acv := <-s.asyncCh:
-			go s.sendAsyncValues(acv, working)
+			s.asyncPool.Submit(ctx, acv)
 		}
 	}
 }
 
+// newAsyncWorkerPool creates the worker pool processing the AsyncValues. The
+// number of workers defaults to Service.AsyncBufferSize, which used to bound
+// the concurrency before, and their queues share Service.AsyncBufferSize slots.
+func (s *DeviceService) newAsyncWorkerPool() (*async.WorkerPool, error) {
+	workers := s.config.Service.AsyncWorkers
+	if workers <= 0 {
+		workers = s.config.Service.AsyncBufferSize
+	}
+	return async.NewWorkerPool(workers, s.config.Service.AsyncBufferSize, s.config.Service.AsyncOverflowPolicy, s.sendAsyncValues, s.LoggingClient)
+}
+
+// newEventBatcher creates the batcher of the async events, or nil if batching is disabled.
+func (s *DeviceService) newEventBatcher() (*async.EventBatcher, error) {
+	if s.config.Service.AsyncBatchSize < 2 {
+		return nil, nil
+	}
+	linger, err := time.ParseDuration(s.config.Service.AsyncBatchLinger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AsyncBatchLinger %s: %v", s.config.Service.AsyncBatchLinger, err)
+	}
+	if linger <= 0 {
+		return nil, fmt.Errorf("AsyncBatchLinger %s must be positive", s.config.Service.AsyncBatchLinger)
+	}
+	return async.NewEventBatcher(s.config.Service.AsyncBatchSize, linger, s.tedgeClients.EventClient, s.LoggingClient), nil
+}
+
 // sendAsyncValues convert AsyncValues to event and send the event to CoreData
-func (s *DeviceService) sendAsyncValues(acv *dsModels.AsyncValues, working chan bool) {
-	working <- true
-	defer func() {
-		<-working
-	}()
+func (s *DeviceService) sendAsyncValues(acv *dsModels.AsyncValues) {
 	readings := make([]models.Reading, 0, len(acv.CommandValues))
 
 	device, ok := cache.Devices().ForName(acv.DeviceName)
 	if !ok {
 		s.LoggingClient.Error(fmt.Sprintf("processAsyncResults - recieved Device %s not found in cache", acv.DeviceName))
+		err := fmt.Errorf("device %s not found", acv.DeviceName)
+		for _, cv := range acv.CommandValues {
+			s.rejections.Reject(acv.DeviceName, *cv, dsModels.RejectUnknownDevice, err)
+		}
 		return
 	}
 
+	// the CommandValues as pushed by the driver, reported if the event cannot be sent
+	values := s.rejections.EventValues(acv.DeviceName)
 	for _, cv := range acv.CommandValues {
+		i := values.Add(*cv)
+
 		// get the device resource associated with the rsp.RO
 		dr, ok := cache.Profiles().DeviceResource(device.ProfileName, cv.DeviceResourceName)
 		if !ok {
 			s.LoggingClient.Error(fmt.Sprintf("processAsyncResults - Device Resource %s not found in Device %s", cv.DeviceResourceName, acv.DeviceName))
+			values.Reject(i, dsModels.RejectUnknownResource,
+				fmt.Errorf("device resource %s not found in profile %s", cv.DeviceResourceName, device.ProfileName))
 			continue
 		}
 
@@ -81,6 +114,7 @@ func (s *DeviceService) sendAsyncValues(acv *dsModels.AsyncValues, working chan
 			err := transformer.TransformReadResult(cv, dr.Properties, s.LoggingClient)
 			if err != nil {
 				s.LoggingClient.Error(fmt.Sprintf("processAsyncResults - CommandValue (%s) transformed failed: %v", cv.String(), err))
+				values.Reject(i, dsModels.RejectTransformFailed, err)
 
 				if errors.As(err, &transformer.OverflowError{}) {
 					cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, transformer.Overflow)
@@ -95,6 +129,7 @@ func (s *DeviceService) sendAsyncValues(acv *dsModels.AsyncValues, working chan
 		err := transformer.CheckAssertion(cv, dr.Properties.Assertion, &device, s.LoggingClient, s.tedgeClients.DeviceClient)
 		if err != nil {
 			s.LoggingClient.Error(fmt.Sprintf("processAsyncResults - Assertion failed for device resource: %s, with value: %s and assertion: %s, %v", cv.DeviceResourceName, cv.String(), dr.Properties.Assertion, err))
+			values.Reject(i, dsModels.RejectAssertionFailed, err)
 			cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Assertion))
 		}
 
@@ -126,7 +161,21 @@ func (s *DeviceService) sendAsyncValues(acv *dsModels.AsyncValues, working chan
 		Readings:    readings,
 	}
 
-	common.SendEvent(dtos.FromEventModelToDTO(event), s.LoggingClient, s.tedgeClients.EventClient)
+	eventDTO := dtos.FromEventModelToDTO(event)
+	command.EnrichEvent(&eventDTO, device, s.config.Device.Enrichment)
+
+	// the values already rejected are sent as their replacement readings, they are
+	// not rejected again
+	sinkFailed := func(err edgexErr.EdgeX) {
+		values.SinkFailed(err)
+	}
+	if s.eventBatcher != nil {
+		s.eventBatcher.Add(eventDTO, sinkFailed)
+		return
+	}
+	if err := common.SendEvent(eventDTO, s.LoggingClient, s.tedgeClients.EventClient); err != nil {
+		sinkFailed(err)
+	}
 }
 
 // processAsyncFilterAndAdd filter and add devices discovered by
@@ -136,91 +185,125 @@ func (s *DeviceService) processAsyncFilterAndAdd(ctx context.Context, wg *sync.W
 	defer func() {
 		wg.Done()
 	}()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case devices := <-s.deviceCh:
-			newDevReqs := make([]requests.AddDeviceRequest, 0, len(devices))
-			ctx := context.Background()
-			pws := cache.ProvisionWatchers().All()
-			for _, d := range devices {
-				for _, pw := range pws {
-					if whitelistPass(d, pw, s.LoggingClient) && blacklistPass(d, pw, s.LoggingClient) {
-						if _, ok := cache.Devices().ForName(d.Name); ok {
-							s.LoggingClient.Debug(fmt.Sprintf("Candidate discovered device %s already existed", d.Name))
-							break
-						}
-						s.LoggingClient.Info(fmt.Sprintf("Adding discovered device %s to Edgex", d.Name))
-						millis := time.Now().UnixNano() / 1e6
-						device := models.Device{
-							Name:           d.Name,
-							ProfileName:    pw.ProfileName,
-							Protocols:      d.Protocols,
-							Labels:         d.Labels,
-							ServiceName:    pw.ServiceName,
-							AdminState:     pw.AdminState,
-							OperatingState: models.Up,
-							AutoEvents:     nil,
-						}
-						device.Created = millis
-						device.Description = d.Description
-						// models to dtos
-						newDevReqs = append(newDevReqs, requests.AddDeviceRequest{
-							BaseRequest: commonDTO.NewBaseRequest(),
-							Device:      dtos.FromDeviceModelToDTO(device),
-						})
-					}
-				}
-				// add here
-				_, err := s.tedgeClients.DeviceClient.Add(ctx, newDevReqs)
-				if err != nil {
-					s.LoggingClient.Error(fmt.Sprintf("failed to create discovered device %v", err))
-				} else {
-					break
-				}
-			}
-			s.LoggingClient.Debug("Filtered device addition finished")
-		}
-	}
+	autodiscovery.ProcessBatches(ctx, s.deviceCh, s.filterAndAdd)
 }
 
-func whitelistPass(d dsModels.DiscoveredDevice, pw models.ProvisionWatcher, lc logger.LoggingClient) bool {
-	// ignore the device protocol properties name
-	for _, protocol := range d.Protocols {
-		matchedCount := 0
-		for name, regex := range pw.Identifiers {
-			if value, ok := protocol[name]; ok {
-				matched, err := regexp.MatchString(regex, value)
-				if !matched || err != nil {
-					lc.Debug(fmt.Sprintf("Device %s's %s value %s did not match PW identifier: %s", d.Name, name, value, regex))
-					break
-				}
-				matchedCount += 1
+// filterAndAdd filters a batch of discovered devices through the provision watchers
+// and adds the accepted ones.
+// A discovered device having the identity of an existing device, as declared by
+// the IdentityKeys of the driver or the ProvisionWatcher, updates the protocol
+// properties of the existing device instead of being added.
+// The discovery job results name the devices as discovered.
+func (s *DeviceService) filterAndAdd(devices []dsModels.DiscoveredDevice) {
+	newDevReqs := make([]requests.AddDeviceRequest, 0, len(devices))
+	var updateReqs []requests.UpdateDeviceRequest
+	// the discovered names of the devices added and updated, results are recorded under them
+	var newDevNames, updateNames []string
+	ctx := context.Background()
+	pws := cache.ProvisionWatchers().All()
+	existing := cache.Devices().All()
+	var pending []models.Device
+	added := make(map[string]bool)
+	updated := make(map[string]bool)
+	for _, d := range devices {
+		autodiscovery.RecordFound(d.Name)
+		pw, ok := provision.FirstAccepting(d, pws)
+		if !ok {
+			s.LoggingClient.Debug(fmt.Sprintf("Discovered device %s not accepted by any provision watcher", d.Name))
+			autodiscovery.RecordRejected(d.Name, "not accepted by any provision watcher")
+			continue
+		}
+		name, err := provision.DeviceName(d, pw)
+		if err != nil {
+			s.LoggingClient.Error(fmt.Sprintf("Discovered device %s cannot be named: %v", d.Name, err))
+			autodiscovery.RecordRejected(d.Name, err.Error())
+			continue
+		}
+		keys := provision.IdentityKeys(d, pw)
+		if device, ok := provision.FindByIdentity(d, keys, existing); ok {
+			protocols, changed := provision.MergeProtocols(device.Protocols, d.Protocols)
+			if !changed || updated[device.Name] {
+				s.LoggingClient.Debug(fmt.Sprintf("Discovered device %s is the existing device %s", d.Name, device.Name))
+				autodiscovery.RecordRejected(d.Name, fmt.Sprintf("device %s already exists", device.Name))
+				continue
 			}
+			updated[device.Name] = true
+			s.LoggingClient.Info(fmt.Sprintf("Updating the protocol properties of device %s rediscovered as %s", device.Name, d.Name))
+			deviceName := device.Name
+			updateReqs = append(updateReqs, requests.UpdateDeviceRequest{
+				BaseRequest: commonDTO.NewBaseRequest(),
+				Device: dtos.UpdateDevice{
+					Name:      &deviceName,
+					Protocols: dtos.FromProtocolModelsToDTOs(protocols),
+				},
+			})
+			updateNames = append(updateNames, d.Name)
+			continue
+		}
+		if device, ok := provision.FindByIdentity(d, keys, pending); ok {
+			s.LoggingClient.Debug(fmt.Sprintf("Discovered device %s is the discovered device %s", d.Name, device.Name))
+			autodiscovery.RecordRejected(d.Name, fmt.Sprintf("device %s already exists", device.Name))
+			continue
 		}
-		// match succeed on all identifiers
-		if matchedCount == len(pw.Identifiers) {
-			return true
+		if _, ok := cache.Devices().ForName(name); ok || added[name] {
+			s.LoggingClient.Debug(fmt.Sprintf("Candidate discovered device %s already existed", name))
+			autodiscovery.RecordRejected(d.Name, fmt.Sprintf("device %s already exists", name))
+			continue
+		}
+		added[name] = true
+		s.LoggingClient.Info(fmt.Sprintf("Adding discovered device %s to Edgex, accepted by provision watcher %s", name, pw.Name))
+		millis := time.Now().UnixNano() / 1e6
+		device := models.Device{
+			Name:           name,
+			ProfileName:    pw.ProfileName,
+			Protocols:      d.Protocols,
+			Labels:         provision.DeviceLabels(d, pw),
+			ServiceName:    pw.ServiceName,
+			AdminState:     pw.AdminState,
+			OperatingState: models.Up,
+			AutoEvents:     pw.AutoEvents,
 		}
+		device.Created = millis
+		device.Description = d.Description
+		pending = append(pending, device)
+		// models to dtos
+		newDevReqs = append(newDevReqs, requests.AddDeviceRequest{
+			BaseRequest: commonDTO.NewBaseRequest(),
+			Device:      dtos.FromDeviceModelToDTO(device),
+		})
+		newDevNames = append(newDevNames, d.Name)
 	}
-	return false
-}
-
-func blacklistPass(d dsModels.DiscoveredDevice, pw models.ProvisionWatcher, lc logger.LoggingClient) bool {
-	// a candidate should match none of the blocking identifiers
-	for name, blacklist := range pw.BlockingIdentifiers {
-		// ignore the device protocol properties name
-		for _, protocol := range d.Protocols {
-			if value, ok := protocol[name]; ok {
-				for _, v := range blacklist {
-					if value == v {
-						lc.Debug(fmt.Sprintf("Discovered Device %s's %s should not be %s", d.Name, name, value))
-						return false
-					}
-				}
+	if len(newDevReqs) > 0 {
+		responses, err := s.tedgeClients.DeviceClient.Add(ctx, newDevReqs)
+		if err != nil {
+			s.LoggingClient.Error(fmt.Sprintf("failed to create discovered device %v", err))
+		}
+		for i, name := range newDevNames {
+			switch {
+			case err != nil:
+				autodiscovery.RecordRejected(name, err.Error())
+			case i < len(responses) && responses[i].StatusCode >= http.StatusMultipleChoices:
+				autodiscovery.RecordRejected(name, fmt.Sprintf("%v", responses[i].Message))
+			default:
+				autodiscovery.RecordAdded(name)
+			}
+		}
+	}
+	if len(updateReqs) > 0 {
+		responses, err := s.tedgeClients.DeviceClient.Update(ctx, updateReqs)
+		if err != nil {
+			s.LoggingClient.Error(fmt.Sprintf("failed to update rediscovered device %v", err))
+		}
+		for i, name := range updateNames {
+			switch {
+			case err != nil:
+				autodiscovery.RecordRejected(name, err.Error())
+			case i < len(responses) && responses[i].StatusCode >= http.StatusMultipleChoices:
+				autodiscovery.RecordRejected(name, fmt.Sprintf("%v", responses[i].Message))
+			default:
+				autodiscovery.RecordUpdated(name)
 			}
 		}
 	}
-	return true
+	s.LoggingClient.Debug("Filtered device addition finished")
 }
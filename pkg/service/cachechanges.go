@@ -0,0 +1,27 @@
+package service
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	dsModels "github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
+)
+
+// SubscribeDeviceChanges calls handler with the before and after snapshots of every
+// change of the cached devices, whether made by a metadata callback, the cache
+// reconciliation, the discovery or the managed AutoEvents. Each subscriber receives
+// the changes in order on its own goroutine. Calling the returned function ends
+// the subscription.
+func (s *DeviceService) SubscribeDeviceChanges(handler func(change dsModels.DeviceChange)) (unsubscribe func()) {
+	return cache.SubscribeDeviceChanges(handler)
+}
+
+// SubscribeProfileChanges calls handler for every change of the cached profiles,
+// with the same delivery as SubscribeDeviceChanges.
+func (s *DeviceService) SubscribeProfileChanges(handler func(change dsModels.ProfileChange)) (unsubscribe func()) {
+	return cache.SubscribeProfileChanges(handler)
+}
+
+// SubscribeProvisionWatcherChanges calls handler for every change of the cached
+// provision watchers, with the same delivery as SubscribeDeviceChanges.
+func (s *DeviceService) SubscribeProvisionWatcherChanges(handler func(change dsModels.ProvisionWatcherChange)) (unsubscribe func()) {
+	return cache.SubscribeProvisionWatcherChanges(handler)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+)
+
+// DevicesByLabel returns the devices of the device service having the label,
+// sorted by name
+func (s *DeviceService) DevicesByLabel(label string) []models.Device {
+	return cache.Devices().ForLabel(label)
+}
+
+// DevicesByProfile returns the devices of the device service using the profile,
+// sorted by name
+func (s *DeviceService) DevicesByProfile(profileName string) []models.Device {
+	return cache.Devices().ForProfile(profileName)
+}
+
+// DevicesByProtocolProperty returns the devices of the device service having the
+// protocol property key set to the value in any of their protocols, such as all the
+// devices with the "Port" property set to "COM2", sorted by name
+func (s *DeviceService) DevicesByProtocolProperty(key string, value string) []models.Device {
+	return cache.Devices().ForProtocolProperty(key, value)
+}
+
+// DevicesByOperatingState returns the devices of the device service in the operating
+// state, sorted by name
+func (s *DeviceService) DevicesByOperatingState(state models.OperatingState) []models.Device {
+	return cache.Devices().ForOperatingState(state)
+}
@@ -17,8 +17,10 @@ import (
 	"github.com/edgexfoundry/go-mod-bootstrap/v2/di"
 	"github.com/gorilla/mux"
 
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/async"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autoevent"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/cache"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/callback"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/container"
 	"github.com/tuya/tuya-edge-driver-sdk-go/pkg/models"
 )
@@ -45,14 +47,35 @@ func (b *Bootstrap) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup, st
 	}
 
 	// initialize devices, deviceResources, provisionWatchers & profiles cache
-	cache.InitCache(
+	stale := cache.InitCache(
 		ds.deviceService.Name,
 		ds.LoggingClient,
 		container.MetadataDeviceProfileClientFrom(dic.Get),
 		container.MetadataDeviceClientFrom(dic.Get),
-		container.MetadataProvisionWatcherClientFrom(dic.Get))
+		container.MetadataProvisionWatcherClientFrom(dic.Get),
+		ds.config.Service.CacheSnapshotFile)
+	if ds.config.Service.CacheSnapshotFile != "" {
+		cache.RunSnapshotWriter(ctx, wg, ds.config.Service.CacheSnapshotFile, ds.LoggingClient)
+	}
 
 	if ds.AsyncReadings() {
+		pool, err := ds.newAsyncWorkerPool()
+		if err != nil {
+			ds.LoggingClient.Error(fmt.Sprintf("failed to create async worker pool: %v", err))
+			return false
+		}
+		batcher, err := ds.newEventBatcher()
+		if err != nil {
+			ds.LoggingClient.Error(fmt.Sprintf("failed to create async event batcher: %v", err))
+			return false
+		}
+		if batcher != nil {
+			ds.eventBatcher = batcher
+			ds.eventBatcher.Run(ctx, wg)
+		}
+		ds.rejections = async.NewRejections(ds.driver)
+		ds.asyncPool = pool
+		ds.asyncPool.Run(ctx, wg)
 		ds.asyncCh = make(chan *models.AsyncValues, ds.config.Service.AsyncBufferSize)
 		go ds.processAsyncResults(ctx, wg)
 	}
@@ -84,11 +107,34 @@ func (b *Bootstrap) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup, st
 		container.ProtocolDriverName: func(get di.Get) interface{} {
 			return ds.driver
 		},
+		container.AsyncWorkerPoolName: func(get di.Get) interface{} {
+			return ds.asyncPool
+		},
+		container.AsyncRejectionsName: func(get di.Get) interface{} {
+			return ds.rejections
+		},
 	})
 
 	ds.controller.InitRestRoutes()
 
 	autoevent.GetManager().StartAutoEvents(dic)
+	if interval := ds.config.Service.CacheReconcileInterval; interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			ds.LoggingClient.Error(fmt.Sprintf("invalid CacheReconcileInterval %s: %v", interval, err))
+			return false
+		}
+		if d > 0 {
+			callback.RunReconciler(ctx, wg, d, dic)
+		}
+	}
+	if stale {
+		retry := time.Millisecond * time.Duration(ds.config.Service.Timeout)
+		if retry <= 0 {
+			retry = time.Second
+		}
+		callback.ReconcileWhenAvailable(ctx, wg, retry, dic)
+	}
 	http.TimeoutHandler(nil, time.Millisecond*time.Duration(ds.config.Service.Timeout), "Request timed out")
 
 	return true
@@ -25,7 +25,7 @@ func (s *DeviceService) AddDeviceAutoEvent(deviceName string, event models.AutoE
 	}
 
 	for _, e := range device.AutoEvents {
-		if e.Resource == event.Resource {
+		if e.Resource == event.Resource && e.Tag == event.Tag {
 			s.LoggingClient.Debug(fmt.Sprintf("Updating existing auto event %s for device %s\n", e.Resource, deviceName))
 			e.Frequency = event.Frequency
 			e.OnChange = event.OnChange
@@ -56,7 +56,7 @@ func (s *DeviceService) RemoveDeviceAutoEvent(deviceName string, event models.Au
 
 	autoevent.GetManager().StopForDevice(deviceName)
 	for i, e := range device.AutoEvents {
-		if e.Resource == event.Resource {
+		if e.Resource == event.Resource && e.Tag == event.Tag {
 			s.LoggingClient.Debug(fmt.Sprintf("Removing auto event %s for device %s\n", e.Resource, deviceName))
 			device.AutoEvents = append(device.AutoEvents[:i], device.AutoEvents[i+1:]...)
 			break
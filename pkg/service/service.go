@@ -28,6 +28,7 @@ import (
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/dtos/requests"
 	eErr "github.com/tuya/tuya-edge-driver-sdk-go/contracts/errors"
 	"github.com/tuya/tuya-edge-driver-sdk-go/contracts/models"
+	"github.com/tuya/tuya-edge-driver-sdk-go/internal/async"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/autoevent"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/clients"
 	"github.com/tuya/tuya-edge-driver-sdk-go/internal/common"
@@ -51,6 +52,9 @@ type DeviceService struct {
 	driver        dsModels.ProtocolDriver
 	discovery     dsModels.ProtocolDiscovery
 	asyncCh       chan *dsModels.AsyncValues
+	asyncPool     *async.WorkerPool
+	eventBatcher  *async.EventBatcher
+	rejections    *async.Rejections
 	deviceCh      chan []dsModels.DiscoveredDevice
 	initialized   bool
 }
@@ -122,6 +126,13 @@ func (s *DeviceService) AddRoute(route string, handler func(http.ResponseWriter,
 	return s.controller.AddRoute(route, handler, methods...)
 }
 
+// AddAuthenticator adds an authentication method of the REST requests, which the auth
+// policies of the configuration can then accept by name, or replaces a built-in one:
+// apikey, hmac or jwt.
+func (s *DeviceService) AddAuthenticator(method string, authenticator dsModels.Authenticator) {
+	s.controller.AddAuthenticator(method, authenticator)
+}
+
 // Stop shuts down the Service
 func (s *DeviceService) Stop(force bool) {
 	if s.initialized {